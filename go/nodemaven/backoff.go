@@ -0,0 +1,59 @@
+package nodemaven
+
+import (
+	"math"
+	mrand "math/rand"
+	"time"
+)
+
+// Backoff computes exponential retry delays with optional jitter.
+// The zero value is usable and behaves like DefaultBackoff.
+type Backoff struct {
+	// Base is the delay before the first retry
+	Base time.Duration
+	// Max caps the delay of any single retry
+	Max time.Duration
+	// Factor multiplies the delay after every attempt
+	Factor float64
+	// Jitter randomizes each delay by up to this fraction (0 disables, 0.2 means ±20%)
+	Jitter float64
+}
+
+// DefaultBackoff returns the backoff policy used by the client for retries
+func DefaultBackoff() *Backoff {
+	return &Backoff{
+		Base:   500 * time.Millisecond,
+		Max:    30 * time.Second,
+		Factor: 2,
+		Jitter: 0.2,
+	}
+}
+
+// Next returns the delay to wait before retry number attempt (starting at 0)
+func (b *Backoff) Next(attempt int) time.Duration {
+	base, max, factor := b.Base, b.Max, b.Factor
+	if base <= 0 {
+		base = 500 * time.Millisecond
+	}
+	if max <= 0 {
+		max = 30 * time.Second
+	}
+	if factor < 1 {
+		factor = 2
+	}
+	if attempt < 0 {
+		attempt = 0
+	}
+
+	delay := float64(base) * math.Pow(factor, float64(attempt))
+	if delay > float64(max) {
+		delay = float64(max)
+	}
+
+	if b.Jitter > 0 {
+		jitter := math.Min(b.Jitter, 1)
+		delay *= 1 + jitter*(2*mrand.Float64()-1)
+	}
+
+	return time.Duration(delay)
+}
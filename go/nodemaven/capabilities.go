@@ -0,0 +1,116 @@
+package nodemaven
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// capabilityEndpoints are the endpoints probed by Capabilities
+var capabilityEndpoints = []string{
+	EndpointUserInfo,
+	EndpointCountries,
+	EndpointRegions,
+	EndpointCities,
+	EndpointStatistics,
+}
+
+// Capabilities describes which API endpoints the server supports
+type Capabilities struct {
+	// Endpoints maps each probed endpoint to whether the server supports it
+	Endpoints map[string]bool
+	// Methods holds the methods advertised in the Allow header, when the server sends one
+	Methods map[string][]string
+	// Probed is false when the server does not answer OPTIONS requests; every endpoint
+	// is then assumed to be supported
+	Probed bool
+}
+
+// Supports reports whether endpoint is supported. Endpoints that were not probed are
+// assumed to be supported.
+func (c *Capabilities) Supports(endpoint string) bool {
+	supported, ok := c.Endpoints[endpoint]
+	return !ok || supported
+}
+
+// SupportsMethod reports whether the server advertised method for endpoint. When no
+// Allow header was received the method is assumed to be supported.
+func (c *Capabilities) SupportsMethod(endpoint, method string) bool {
+	if !c.Supports(endpoint) {
+		return false
+	}
+	methods, ok := c.Methods[endpoint]
+	if !ok {
+		return true
+	}
+	for _, m := range methods {
+		if strings.EqualFold(m, method) {
+			return true
+		}
+	}
+	return false
+}
+
+// Capabilities probes the API with OPTIONS requests to find out which endpoints the server
+// supports, so features can be enabled only where available. Servers that do not answer
+// OPTIONS degrade gracefully: all endpoints are reported as supported and Probed is false.
+func (c *Client) Capabilities(ctx context.Context) (*Capabilities, error) {
+	caps := &Capabilities{
+		Endpoints: make(map[string]bool, len(capabilityEndpoints)),
+		Methods:   make(map[string][]string),
+	}
+
+	for _, endpoint := range capabilityEndpoints {
+		allow, err := c.probeEndpoint(ctx, endpoint)
+
+		var (
+			notFoundErr  *NotFoundError
+			authErr      *AuthenticationError
+			forbiddenErr *ForbiddenError
+			apiErr       apiErrorer
+		)
+		switch {
+		case err == nil:
+			caps.Endpoints[endpoint] = true
+			caps.Probed = true
+			if allow != "" {
+				caps.Methods[endpoint] = parseAllowHeader(allow)
+			}
+		case errors.As(err, &notFoundErr):
+			caps.Endpoints[endpoint] = false
+			caps.Probed = true
+		case errors.As(err, &authErr), errors.As(err, &forbiddenErr):
+			return nil, err
+		case errors.As(err, &apiErr):
+			// 405, 501 and the like: the server does not describe itself
+			caps.Endpoints[endpoint] = true
+		default:
+			return nil, fmt.Errorf("capabilities probe failed: %w", err)
+		}
+	}
+
+	return caps, nil
+}
+
+// probeEndpoint sends an OPTIONS request to endpoint through the client's request path and
+// returns the Allow header
+func (c *Client) probeEndpoint(ctx context.Context, endpoint string) (string, error) {
+	_, header, err := c.DoRaw(ctx, http.MethodOptions, endpoint, nil, nil)
+	if err != nil {
+		return "", err
+	}
+	return header.Get("Allow"), nil
+}
+
+// parseAllowHeader splits an Allow header into upper-cased method names
+func parseAllowHeader(allow string) []string {
+	var methods []string
+	for _, method := range strings.Split(allow, ",") {
+		if method = strings.ToUpper(strings.TrimSpace(method)); method != "" {
+			methods = append(methods, method)
+		}
+	}
+	return methods
+}
@@ -0,0 +1,64 @@
+package nodemaven
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestCapabilities(t *testing.T) {
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodOptions {
+			t.Errorf("probe of %s used %s, want OPTIONS", r.URL.Path, r.Method)
+		}
+		if r.Header.Get("Authorization") != "x-api-key test-key" || r.Header.Get(RequestIDHeader) == "" {
+			t.Errorf("probe of %s is missing the client headers: %v", r.URL.Path, r.Header)
+		}
+		switch r.URL.Path {
+		case EndpointUserInfo:
+			w.Header().Set("Allow", "GET, options")
+		case EndpointCountries:
+			http.NotFound(w, r)
+		case EndpointRegions:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+	var debug bytes.Buffer
+	client.DebugWriter = &debug
+
+	caps, err := client.Capabilities(context.Background())
+	if err != nil {
+		t.Fatalf("Capabilities: %v", err)
+	}
+	if !caps.Probed {
+		t.Error("Probed = false, want true")
+	}
+	if !caps.SupportsMethod(EndpointUserInfo, "OPTIONS") || caps.SupportsMethod(EndpointUserInfo, "POST") {
+		t.Errorf("methods for %s = %v, want GET and OPTIONS", EndpointUserInfo, caps.Methods[EndpointUserInfo])
+	}
+	if caps.Supports(EndpointCountries) {
+		t.Errorf("%s reported as supported after a 404", EndpointCountries)
+	}
+	if !caps.Supports(EndpointRegions) || !caps.Supports(EndpointStatistics) {
+		t.Error("endpoints answering 405 or 200 reported as unsupported")
+	}
+	if !strings.Contains(debug.String(), "OPTIONS") || strings.Contains(debug.String(), "test-key") {
+		t.Errorf("probes were not logged through the redacting DebugWriter:\n%s", debug.String())
+	}
+}
+
+func TestCapabilitiesAuthError(t *testing.T) {
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"detail": "Invalid API key"}`))
+	})
+
+	_, err := client.Capabilities(context.Background())
+	var authErr *AuthenticationError
+	if !errors.As(err, &authErr) || authErr.Message != "Invalid API key" {
+		t.Errorf("error = %v, want *AuthenticationError carrying the API message", err)
+	}
+}
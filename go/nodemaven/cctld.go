@@ -0,0 +1,90 @@
+package nodemaven
+
+import (
+	"net"
+	"net/url"
+	"strings"
+)
+
+// ccTLDCountries maps country-code TLDs that differ from the ISO 3166-1 code of their country.
+// Every other two-letter TLD is its country's ISO code and is looked up in countryContinents,
+// which also leaves out TLDs of no single country such as .eu and .su.
+var ccTLDCountries = map[string]string{
+	"uk": "GB", // United Kingdom
+	"ac": "SH", // Ascension Island, part of Saint Helena
+	"tp": "TL", // former TLD of Timor-Leste
+}
+
+// vanityTLDs are country-code TLDs mostly registered as generic domains (e.g. .io for tech
+// companies), so they say little about the site's audience
+var vanityTLDs = map[string]bool{
+	"ac": true, "ai": true, "cc": true, "co": true, "fm": true, "gg": true, "io": true,
+	"ly": true, "me": true, "sh": true, "to": true, "tv": true, "ws": true,
+}
+
+// secondLevelLabels are labels under which country registries offer domains
+// (example.com.au, example.co.uk); a vanity TLD below one of them is used as a real ccTLD
+var secondLevelLabels = map[string]bool{
+	"ac": true, "co": true, "com": true, "edu": true, "gob": true, "gov": true,
+	"ne": true, "net": true, "or": true, "org": true,
+}
+
+// CountryFromURL infers the ISO country code (uppercase) of a site from its URL's
+// country-code TLD, e.g. "https://shop.example.de" gives "DE" and "example.co.uk" gives "GB".
+// URLs without a scheme are accepted. An empty string is returned for generic TLDs (.com,
+// .org), IP addresses, unknown codes and vanity ccTLDs such as .io or .tv, unless they are
+// used below a registry label as in example.com.co.
+func CountryFromURL(rawURL string) string {
+	host := rawURL
+	if strings.Contains(rawURL, "://") {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return ""
+		}
+		host = u.Hostname()
+	} else if u, err := url.Parse("//" + rawURL); err == nil {
+		host = u.Hostname()
+	}
+
+	if net.ParseIP(host) != nil {
+		return ""
+	}
+
+	labels := strings.Split(strings.TrimSuffix(strings.ToLower(host), "."), ".")
+	if len(labels) < 2 {
+		return ""
+	}
+	tld := labels[len(labels)-1]
+	if len(tld) != 2 {
+		return ""
+	}
+
+	if vanityTLDs[tld] && !(len(labels) >= 3 && secondLevelLabels[labels[len(labels)-2]]) {
+		return ""
+	}
+	if country, ok := ccTLDCountries[tld]; ok {
+		return country
+	}
+	if code := strings.ToUpper(tld); countryContinents[code] != "" {
+		return code
+	}
+	return ""
+}
+
+// ProxyOptionsForURL returns a copy of options targeting the country inferred from rawURL by
+// CountryFromURL, for proxying a geo-restricted site from its own country. When no country
+// can be inferred, options are returned unchanged (copied), keeping any Country they set.
+func ProxyOptionsForURL(rawURL string, options *ProxyOptions) *ProxyOptions {
+	targeted := copyProxyOptions(options)
+	if targeted == nil {
+		targeted = &ProxyOptions{}
+	}
+
+	if country := CountryFromURL(rawURL); country != "" && !strings.EqualFold(targeted.Country, country) {
+		targeted.Country = strings.ToLower(country)
+		// A region or city of another country no longer applies
+		targeted.Region, targeted.Regions = "", nil
+		targeted.City, targeted.Cities = "", nil
+	}
+	return targeted
+}
@@ -0,0 +1,58 @@
+package nodemaven
+
+import (
+	"testing"
+)
+
+func TestCountryFromURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://shop.example.de/path?q=1", "DE"},
+		{"https://www.example.co.uk", "GB"},
+		{"https://example.uk", "GB"},
+		{"http://news.example.com.au:8080/", "AU"},
+		{"https://example.fr.", "FR"},
+		{"https://EXAMPLE.JP", "JP"},
+		{"https://startup.io", ""},
+		{"https://startup.tv", ""},
+		{"https://example.com.co", "CO"},
+		{"https://example.com", ""},
+		{"https://example.org", ""},
+		{"https://example.eu", ""},
+		{"https://example.zz", ""},
+		{"http://192.168.1.10/admin", ""},
+		{"http://[2001:db8::1]:8080/", ""},
+		{"10.0.0.1", ""},
+		{"example.de", "DE"},
+		{"shop.example.com.br/cart", "BR"},
+		{"example.co.uk:443", "GB"},
+		{"localhost", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := CountryFromURL(tt.url); got != tt.want {
+			t.Errorf("CountryFromURL(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestProxyOptionsForURL(t *testing.T) {
+	options := &ProxyOptions{Country: "us", Region: "california", ISP: "comcast"}
+
+	targeted := ProxyOptionsForURL("https://example.co.uk", options)
+	if targeted.Country != "gb" || targeted.Region != "" || targeted.ISP != "comcast" {
+		t.Errorf("ProxyOptionsForURL(.co.uk) = %+v, want country gb without the US region", targeted)
+	}
+	if options.Country != "us" || options.Region != "california" {
+		t.Error("ProxyOptionsForURL modified its options")
+	}
+
+	if kept := ProxyOptionsForURL("https://example.com", options); kept.Country != "us" || kept.Region != "california" {
+		t.Errorf("ProxyOptionsForURL(.com) = %+v, want options unchanged", kept)
+	}
+	if targeted := ProxyOptionsForURL("example.de", nil); targeted.Country != "de" {
+		t.Errorf("ProxyOptionsForURL(example.de, nil) = %+v, want country de", targeted)
+	}
+}
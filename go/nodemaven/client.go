@@ -6,13 +6,19 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
+	"reflect"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 )
 
@@ -29,9 +35,118 @@ const (
 	DefaultTimeout = 30 * time.Second
 	// UserAgent is the client user agent string
 	UserAgent = "NodeMaven-Go-Client/1.0.0"
+	// RequestIDHeader carries the per-call request ID used to correlate SDK calls with server logs
+	RequestIDHeader = "X-Request-ID"
+	// ProbeTimeout is the per-probe timeout used by ProbeConnectionTypes
+	ProbeTimeout = 10 * time.Second
+	// DefaultPollInterval is the delay between polls of an asynchronous operation
+	DefaultPollInterval = 2 * time.Second
+	// MaxClockSkew is the difference between local and server time above which ClockSkewFunc is called
+	MaxClockSkew = 2 * time.Minute
+	// SampleConcurrency bounds the number of parallel probes made by SampleIPs
+	SampleConcurrency = 10
 )
 
-// Client represents a NodeMaven API client
+// API endpoint paths, relative to BaseURL. They are also the keys of Config.EndpointTimeouts.
+const (
+	EndpointUserInfo   = "/api/v2/base/users/me"
+	EndpointCountries  = "/api/v2/base/locations/countries/"
+	EndpointRegions    = "/api/v2/base/locations/regions/"
+	EndpointCities     = "/api/v2/base/locations/cities/"
+	EndpointStatistics = "/api/v2/base/traffic/statistics/"
+)
+
+// Connection types supported by the proxy gateway
+const (
+	ConnectionTypeResidential = "residential"
+	ConnectionTypeMobile      = "mobile"
+)
+
+// GatewayRegions maps gateway region names to their hostnames, for Config.GatewayRegion.
+// DefaultProxyHost is the only documented gateway, so the table starts empty; register
+// regional gateways here, or pass a hostname as GatewayRegion.
+var GatewayRegions = map[string]string{}
+
+// resolveGatewayRegion returns the hostname for a gateway region. Values containing a dot
+// are taken as a custom gateway hostname.
+func resolveGatewayRegion(region string) (string, error) {
+	if host, ok := GatewayRegions[strings.ToLower(region)]; ok {
+		return host, nil
+	}
+	if strings.Contains(region, ".") {
+		return region, nil
+	}
+
+	if len(GatewayRegions) == 0 {
+		return "", fmt.Errorf("unknown gateway region %q (no regions are registered; pass a hostname)", region)
+	}
+	known := make([]string, 0, len(GatewayRegions))
+	for name := range GatewayRegions {
+		known = append(known, name)
+	}
+	sort.Strings(known)
+	return "", fmt.Errorf("unknown gateway region %q (known: %s; or pass a hostname)", region, strings.Join(known, ", "))
+}
+
+// validateBaseURL checks that baseURL is an absolute https URL. Plain http is only
+// accepted for loopback hosts, such as local test servers.
+func validateBaseURL(baseURL string) error {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return fmt.Errorf("invalid base URL %q: %w", baseURL, err)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("invalid base URL %q: scheme and host are required, e.g. %s", baseURL, DefaultBaseURL)
+	}
+
+	switch u.Scheme {
+	case "https":
+		return nil
+	case "http":
+		if isLoopbackHost(u.Hostname()) {
+			return nil
+		}
+		return fmt.Errorf("invalid base URL %q: https is required (http is only allowed for localhost)", baseURL)
+	default:
+		return fmt.Errorf("invalid base URL %q: unsupported scheme %q", baseURL, u.Scheme)
+	}
+}
+
+// isLoopbackHost reports whether host is localhost or a loopback IP address
+func isLoopbackHost(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// ConnectionTypes lists the known connection types
+var ConnectionTypes = []string{ConnectionTypeResidential, ConnectionTypeMobile}
+
+// isConnectionType reports whether connectionType is one of ConnectionTypes
+func isConnectionType(connectionType string) bool {
+	for _, known := range ConnectionTypes {
+		if connectionType == known {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultConnectionType returns DefaultConnectionType, or residential if it is unset
+func (c *Client) defaultConnectionType() string {
+	if c.DefaultConnectionType != "" {
+		return c.DefaultConnectionType
+	}
+	return ConnectionTypeResidential
+}
+
+// Client represents a NodeMaven API client.
+//
+// A Client is safe for concurrent use. APIKey, BaseURL, ProxyHost, HTTPPort and SOCKS5Port
+// are guarded by SetAPIKey, SetBaseURL, SetProxyHost and SetPorts: once any of these setters
+// may run, the fields must not be read or written directly, since that races with the setters.
 type Client struct {
 	APIKey     string
 	BaseURL    string
@@ -40,6 +155,42 @@ type Client struct {
 	SOCKS5Port int
 	Timeout    time.Duration
 	HTTPClient *http.Client
+	// DefaultProxyOptions are merged under the options passed to GetProxyConfig
+	// and GetSOCKS5ProxyURL; fields set per call take precedence
+	DefaultProxyOptions *ProxyOptions
+	// DefaultConnectionType is used by location queries and proxy configurations that set no
+	// connection type; residential if empty
+	DefaultConnectionType string
+	// UsernameFormat serializes targeting options into the proxy username
+	UsernameFormat UsernameFormat
+	// MaxRetries is how many times failed API requests are retried
+	MaxRetries int
+	// Backoff controls the delay between retries
+	Backoff *Backoff
+	// RetryableErrorFunc decides which failed requests are retried
+	RetryableErrorFunc func(error) bool
+	// CredentialRetries is how many times a user info response without proxy credentials is retried
+	CredentialRetries int
+	// DialTimeout bounds connecting to the proxy gateway for the proxy configurations it creates
+	DialTimeout time.Duration
+	// EndpointTimeouts holds per-endpoint timeouts used when the caller's context has no deadline
+	EndpointTimeouts map[string]time.Duration
+	// DebugWriter receives dumps of raw API exchanges when set
+	DebugWriter io.Writer
+	// ClockSkewFunc is called when the local clock differs from the server's by more than MaxClockSkew
+	ClockSkewFunc func(skew time.Duration)
+	// ErrorMessageFunc overrides the message of API errors when set
+	ErrorMessageFunc func(statusCode int, data map[string]interface{}) string
+	// DryRun answers API calls and proxied requests with synthetic responses, see Config.DryRun
+	DryRun bool
+
+	// mu guards the connection settings against runtime reconfiguration
+	mu            sync.RWMutex
+	lastRequestID string
+	clockSkew     time.Duration
+	profiles      map[string]*ProxyOptions
+	locationTrees *locationTreeCache
+	clock         clock
 }
 
 // Config holds configuration options for the NodeMaven client
@@ -50,6 +201,70 @@ type Config struct {
 	HTTPPort   int
 	SOCKS5Port int
 	Timeout    time.Duration
+	// DefaultProxyOptions holds targeting shared by all proxy configurations of the client
+	DefaultProxyOptions *ProxyOptions
+	// DefaultConnectionType replaces residential as the connection type used when none is
+	// given: by GetCountries, GetRegions, GetCities and their ListAll variants, by
+	// BuildLocationTree and NewTargeting, and by proxy configurations. It must be one of
+	// ConnectionTypes.
+	DefaultConnectionType string
+	// UsernameFormat overrides the proxy username grammar; DefaultUsernameFormat is used if nil
+	UsernameFormat UsernameFormat
+	// MaxRetries enables retrying API requests that fail with network errors,
+	// rate limits (429) or server errors (5xx); 0 disables retries. Only idempotent methods
+	// (GET, HEAD, OPTIONS, PUT, DELETE) are retried, so a POST or PATCH sent through Do or
+	// DoRaw is never submitted twice.
+	MaxRetries int
+	// Backoff is the retry delay policy; DefaultBackoff is used if nil
+	Backoff *Backoff
+	// RetryableErrorFunc decides whether a failed request is retried;
+	// DefaultRetryableError is used if nil
+	RetryableErrorFunc func(error) bool
+	// CredentialRetries retries, with Backoff, user info responses that lack proxy credentials,
+	// as happens briefly while an account is provisioned; 0 disables these retries.
+	// A *CredentialsError is returned if the credentials are still missing afterwards.
+	CredentialRetries int
+	// DialTimeout bounds establishing connections to the proxy gateway, while Timeout keeps
+	// bounding whole API requests and proxied requests. A short DialTimeout fails fast on an
+	// unreachable gateway without cutting off slow but connected transfers. 0 disables it.
+	DialTimeout time.Duration
+	// EndpointTimeouts overrides Timeout for specific endpoints (keyed by the Endpoint* constants),
+	// e.g. a longer timeout for EndpointStatistics.
+	//
+	// Precedence: a deadline on the caller's context always wins and is combined with Timeout.
+	// Only when the context has no deadline is the endpoint timeout applied, replacing Timeout
+	// for that call (including any retries).
+	EndpointTimeouts map[string]time.Duration
+	// DebugWriter, when set, receives a dump of every API request and response for
+	// troubleshooting. The API key and proxy password are redacted from the dumps.
+	DebugWriter io.Writer
+	// ClockSkewFunc, when set, is called with the measured skew (local minus server time)
+	// whenever an API response's Date header is more than MaxClockSkew away from local time.
+	// Skewed clocks are a common cause of rejected statistics date ranges.
+	ClockSkewFunc func(skew time.Duration)
+	// ErrorMessageFunc, when set, builds the Message of API errors from the status code and
+	// decoded error body, e.g. to localize them. Returning "" keeps the default message.
+	// The raw body stays available in NodeMavenError.ErrorData.
+	ErrorMessageFunc func(statusCode int, data map[string]interface{}) string
+	// GatewayRegion pins the proxy gateway to a region registered in GatewayRegions, or to a
+	// custom gateway hostname (any value containing a dot). It replaces DefaultProxyHost and cannot be combined
+	// with ProxyHost.
+	GatewayRegion string
+	// Profiles registers named targeting presets for GetProxyConfigByProfile
+	Profiles map[string]*ProxyOptions
+	// DryRun makes the client work offline, for exercising integration code without calling
+	// the API or consuming traffic. All responses are synthetic: API calls return canned
+	// data (a fake account with placeholder proxy credentials, empty location and statistics
+	// lists, an empty object for other endpoints) and every HTTP client and transport of proxy
+	// configurations (Transport and everything built on it, including ProxyRoundTripper and
+	// HTTPClientWithBypass) answers requests with a JSON body carrying DryRunExitIP. SOCKS5
+	// dialers fail with ErrDryRun and Warmup does nothing. Capabilities reports every endpoint
+	// as supported. Nothing is sent over the network by the client; package helpers such as
+	// GetCurrentIP use the *http.Client passed to them as is.
+	DryRun bool
+
+	// clock overrides the time source, for tests
+	clock clock
 }
 
 // NewClient creates a new NodeMaven client with the given configuration
@@ -72,8 +287,22 @@ func NewClient(config *Config) (*Client, error) {
 	if baseURL == "" {
 		baseURL = getEnvWithDefault("NODEMAVEN_BASE_URL", DefaultBaseURL)
 	}
+	baseURL = strings.TrimRight(baseURL, "/")
+	if err := validateBaseURL(baseURL); err != nil {
+		return nil, err
+	}
 
 	proxyHost := config.ProxyHost
+	if config.GatewayRegion != "" {
+		if proxyHost != "" {
+			return nil, fmt.Errorf("ProxyHost and GatewayRegion cannot be combined")
+		}
+		host, err := resolveGatewayRegion(config.GatewayRegion)
+		if err != nil {
+			return nil, err
+		}
+		proxyHost = host
+	}
 	if proxyHost == "" {
 		proxyHost = getEnvWithDefault("NODEMAVEN_PROXY_HOST", DefaultProxyHost)
 	}
@@ -88,12 +317,33 @@ func NewClient(config *Config) (*Client, error) {
 		socks5Port = getEnvIntWithDefault("NODEMAVEN_SOCKS5_PORT", DefaultSOCKS5Port)
 	}
 
+	backoff := config.Backoff
+	if backoff == nil {
+		backoff = DefaultBackoff()
+	}
+
+	defaultConnectionType := strings.ToLower(config.DefaultConnectionType)
+	if defaultConnectionType != "" && !isConnectionType(defaultConnectionType) {
+		return nil, fmt.Errorf("invalid default connection type %q (known: %s)",
+			config.DefaultConnectionType, strings.Join(ConnectionTypes, ", "))
+	}
+
+	usernameFormat := config.UsernameFormat
+	if usernameFormat == nil {
+		usernameFormat = DefaultUsernameFormat{}
+	}
+
 	timeout := config.Timeout
 	if timeout == 0 {
 		timeoutSecs := getEnvIntWithDefault("REQUEST_TIMEOUT", 30)
 		timeout = time.Duration(timeoutSecs) * time.Second
 	}
 
+	profiles := make(map[string]*ProxyOptions, len(config.Profiles))
+	for name, options := range config.Profiles {
+		profiles[name] = copyProxyOptions(options)
+	}
+
 	return &Client{
 		APIKey:     apiKey,
 		BaseURL:    baseURL,
@@ -102,15 +352,70 @@ func NewClient(config *Config) (*Client, error) {
 		SOCKS5Port: socks5Port,
 		Timeout:    timeout,
 		HTTPClient: &http.Client{Timeout: timeout},
+
+		DefaultProxyOptions:   config.DefaultProxyOptions,
+		DefaultConnectionType: defaultConnectionType,
+		UsernameFormat:        usernameFormat,
+		MaxRetries:            config.MaxRetries,
+		Backoff:               backoff,
+		RetryableErrorFunc:    config.RetryableErrorFunc,
+		CredentialRetries:     config.CredentialRetries,
+		DialTimeout:           config.DialTimeout,
+		EndpointTimeouts:      config.EndpointTimeouts,
+		DebugWriter:           config.DebugWriter,
+		ClockSkewFunc:         config.ClockSkewFunc,
+		ErrorMessageFunc:      config.ErrorMessageFunc,
+		DryRun:                config.DryRun,
+
+		profiles:      profiles,
+		locationTrees: &locationTreeCache{},
+		clock:         config.clock,
 	}, nil
 }
 
-// makeRequest makes an HTTP request to the NodeMaven API
+// makeRequest makes an HTTP request to the NodeMaven API and returns the decoded JSON object
 func (c *Client) makeRequest(ctx context.Context, method, endpoint string, params map[string]string, body interface{}) (map[string]interface{}, error) {
+	var result map[string]interface{}
+	if err := c.makeRequestInto(ctx, method, endpoint, params, body, &result); err != nil {
+		return nil, err
+	}
+	if result == nil {
+		result = make(map[string]interface{})
+	}
+	return result, nil
+}
+
+// Do makes an authenticated request to any API endpoint (relative to BaseURL) and returns
+// the decoded JSON object. It applies the same retries, timeouts and error types as the
+// typed methods, and is useful for endpoints the SDK does not wrap yet.
+func (c *Client) Do(ctx context.Context, method, endpoint string, params map[string]string, body interface{}) (map[string]interface{}, error) {
+	return c.makeRequest(ctx, method, endpoint, params, body)
+}
+
+// rawResponse is a makeRequestInto target that receives the undecoded body and headers
+type rawResponse struct {
+	Body   []byte
+	Header http.Header
+}
+
+// DoRaw makes an authenticated request like Do but returns the raw response body and headers
+// instead of decoding JSON, for endpoints serving other content such as CSV exports or files.
+// Error responses are classified into the same error types as for the typed methods.
+func (c *Client) DoRaw(ctx context.Context, method, endpoint string, params map[string]string, body interface{}) ([]byte, http.Header, error) {
+	raw := &rawResponse{}
+	if err := c.makeRequestInto(ctx, method, endpoint, params, body, raw); err != nil {
+		return nil, nil, err
+	}
+	return raw.Body, raw.Header, nil
+}
+
+// makeRequestInto makes an HTTP request to the NodeMaven API and decodes the JSON
+// response directly into target, without an intermediate map
+func (c *Client) makeRequestInto(ctx context.Context, method, endpoint string, params map[string]string, body interface{}, target interface{}) error {
 	// Build URL
-	u, err := url.Parse(c.BaseURL + endpoint)
+	u, err := c.buildURL(endpoint)
 	if err != nil {
-		return nil, fmt.Errorf("invalid URL: %w", err)
+		return err
 	}
 
 	// Add query parameters
@@ -124,52 +429,171 @@ func (c *Client) makeRequest(ctx context.Context, method, endpoint string, param
 		u.RawQuery = q.Encode()
 	}
 
+	return c.makeRequestURL(ctx, method, endpoint, u, body, target)
+}
+
+// makeRequestURL makes an HTTP request to a fully built API URL and decodes the response into target.
+// endpoint identifies the API endpoint for per-endpoint settings.
+func (c *Client) makeRequestURL(ctx context.Context, method, endpoint string, u *url.URL, body interface{}, target interface{}) error {
 	// Prepare request body
-	var reqBody io.Reader
+	var jsonBody []byte
 	if body != nil {
-		jsonBody, err := json.Marshal(body)
+		var err error
+		jsonBody, err = json.Marshal(body)
 		if err != nil {
-			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+			return fmt.Errorf("failed to marshal request body: %w", err)
 		}
-		reqBody = bytes.NewBuffer(jsonBody)
+	}
+
+	if c.DryRun {
+		return dryRunResponse(endpoint, target)
+	}
+
+	// Apply the per-endpoint timeout in place of the client timeout
+	httpClient := c.HTTPClient
+	if _, hasDeadline := ctx.Deadline(); !hasDeadline {
+		if timeout, ok := c.EndpointTimeouts[endpoint]; ok && timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			withoutTimeout := *httpClient
+			withoutTimeout.Timeout = 0
+			httpClient = &withoutTimeout
+		}
+	}
+
+	// One request ID covers the call, including its retries
+	requestID := GenerateSessionID()
+	c.mu.Lock()
+	c.lastRequestID = requestID
+	c.mu.Unlock()
+
+	for attempt := 0; ; attempt++ {
+		err := c.doRequest(ctx, httpClient, method, u.String(), requestID, jsonBody, target)
+		if err == nil || attempt >= c.MaxRetries || !isIdempotentMethod(method) || !c.isRetryableError(ctx, err) {
+			return err
+		}
+
+		backoff := c.Backoff
+		if backoff == nil {
+			backoff = DefaultBackoff()
+		}
+		// Honor the server's Retry-After (429/503) when it asks for longer than the backoff
+		delay := backoff.Next(attempt)
+		if wait := retryAfter(err); wait > delay {
+			delay = wait
+		}
+		if err := sleepWithClock(ctx, c.getClock(), delay); err != nil {
+			return err
+		}
+
+		// Discard anything a failed attempt decoded so retries never merge stale data
+		resetTarget(target)
+	}
+}
+
+// resetTarget sets the value target points to back to its zero value
+func resetTarget(target interface{}) {
+	if target == nil {
+		return
+	}
+	v := reflect.ValueOf(target)
+	if v.Kind() == reflect.Ptr && !v.IsNil() {
+		v.Elem().Set(reflect.Zero(v.Elem().Type()))
+	}
+}
+
+// doRequest performs a single HTTP request attempt against the NodeMaven API.
+// Successful responses are streamed straight into target with a json.Decoder.
+func (c *Client) doRequest(ctx context.Context, httpClient *http.Client, method, rawURL, requestID string, jsonBody []byte, target interface{}) error {
+	var reqBody io.Reader
+	if jsonBody != nil {
+		reqBody = bytes.NewReader(jsonBody)
 	}
 
 	// Create request
-	req, err := http.NewRequestWithContext(ctx, method, u.String(), reqBody)
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Set headers
-	req.Header.Set("Authorization", "x-api-key "+c.APIKey)
+	req.Header.Set("Authorization", "x-api-key "+c.apiKey())
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json")
+	raw, isRaw := target.(*rawResponse)
+	if isRaw {
+		req.Header.Set("Accept", "*/*")
+	} else {
+		req.Header.Set("Accept", "application/json")
+	}
 	req.Header.Set("User-Agent", UserAgent)
+	req.Header.Set(RequestIDHeader, requestID)
+
+	if c.DebugWriter != nil {
+		c.dumpRequest(req, jsonBody)
+	}
 
 	// Make request
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
+	if c.DebugWriter != nil {
+		c.dumpResponse(resp)
+	}
+	c.checkClockSkew(resp)
+
+	// Handle successful responses
+	if resp.StatusCode < 400 && resp.StatusCode != http.StatusAccepted {
+		if target == nil {
+			return nil
+		}
+		if isRaw {
+			if raw.Body, err = io.ReadAll(resp.Body); err != nil {
+				return fmt.Errorf("failed to read response body: %w", err)
+			}
+			raw.Header = resp.Header
+			return nil
+		}
+		if err := json.NewDecoder(resp.Body).Decode(target); err != nil && err != io.EOF {
+			return fmt.Errorf("failed to unmarshal response: %w", err)
+		}
+		return nil
+	}
+
 	// Read response body
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return fmt.Errorf("failed to read response body: %w", err)
 	}
 
-	// Handle successful responses
-	if resp.StatusCode < 400 {
-		var result map[string]interface{}
-		if len(respBody) > 0 {
-			if err := json.Unmarshal(respBody, &result); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	if resp.StatusCode == http.StatusAccepted {
+		// Asynchronous operations: hand the polling location back to the caller
+		if location := resp.Header.Get("Location"); location != "" {
+			var data map[string]interface{}
+			if len(respBody) > 0 {
+				json.Unmarshal(respBody, &data)
+			}
+			return &AcceptedError{
+				Location:   location,
+				RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), c.getClock().Now()),
+				Data:       data,
 			}
-		} else {
-			result = make(map[string]interface{})
 		}
-		return result, nil
+
+		if isRaw {
+			raw.Body, raw.Header = respBody, resp.Header
+			return nil
+		}
+		if len(respBody) > 0 && target != nil {
+			if err := json.Unmarshal(respBody, target); err != nil {
+				return fmt.Errorf("failed to unmarshal response: %w", err)
+			}
+		}
+		return nil
 	}
 
 	// Handle error responses
@@ -178,20 +602,306 @@ func (c *Client) makeRequest(ctx context.Context, method, endpoint string, param
 		json.Unmarshal(respBody, &errorData)
 	}
 
+	// Prefer the ID echoed by the server in case a gateway rewrote it
+	if echoed := resp.Header.Get(RequestIDHeader); echoed != "" {
+		requestID = echoed
+	}
+
 	errorMsg := parseErrorMessage(errorData, resp.StatusCode, resp.Status)
-	return nil, getExceptionForStatusCode(resp.StatusCode, errorMsg, errorData)
+	if c.ErrorMessageFunc != nil {
+		if msg := c.ErrorMessageFunc(resp.StatusCode, errorData); msg != "" {
+			errorMsg = msg
+		}
+	}
+	retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"), c.getClock().Now())
+	return getExceptionForStatusCode(resp.StatusCode, errorMsg, errorData, requestID, retryAfter)
 }
 
-// GetUserInfo retrieves current user information including proxy credentials and usage data
-func (c *Client) GetUserInfo(ctx context.Context) (*UserInfo, error) {
-	result, err := c.makeRequest(ctx, "GET", "/api/v2/base/users/me", nil, nil)
+// Clone returns an independent copy of the client. Configuration fields are deep-copied,
+// so changing the clone's ProxyHost, ports or options does not affect the original.
+// The clone gets a new http.Client with the same settings that shares the original's
+// Transport, so connection pools are reused.
+func (c *Client) Clone() *Client {
+	settings := c.settings()
+	clone := &Client{
+		APIKey:                settings.apiKey,
+		BaseURL:               settings.baseURL,
+		ProxyHost:             settings.proxyHost,
+		HTTPPort:              settings.httpPort,
+		SOCKS5Port:            settings.socks5Port,
+		Timeout:               c.Timeout,
+		UsernameFormat:        c.UsernameFormat,
+		DefaultConnectionType: c.DefaultConnectionType,
+		MaxRetries:            c.MaxRetries,
+		RetryableErrorFunc:    c.RetryableErrorFunc,
+		CredentialRetries:     c.CredentialRetries,
+		DialTimeout:           c.DialTimeout,
+		DebugWriter:           c.DebugWriter,
+		ClockSkewFunc:         c.ClockSkewFunc,
+		ErrorMessageFunc:      c.ErrorMessageFunc,
+		DryRun:                c.DryRun,
+		locationTrees:         &locationTreeCache{},
+		clock:                 c.clock,
+	}
+
+	if c.HTTPClient != nil {
+		httpClient := *c.HTTPClient
+		clone.HTTPClient = &httpClient
+	}
+	if c.DefaultProxyOptions != nil {
+		clone.DefaultProxyOptions = copyProxyOptions(c.DefaultProxyOptions)
+	}
+	if c.Backoff != nil {
+		backoff := *c.Backoff
+		clone.Backoff = &backoff
+	}
+	if c.EndpointTimeouts != nil {
+		clone.EndpointTimeouts = make(map[string]time.Duration, len(c.EndpointTimeouts))
+		for endpoint, timeout := range c.EndpointTimeouts {
+			clone.EndpointTimeouts[endpoint] = timeout
+		}
+	}
+
+	c.mu.RLock()
+	clone.profiles = make(map[string]*ProxyOptions, len(c.profiles))
+	for name, options := range c.profiles {
+		clone.profiles[name] = copyProxyOptions(options)
+	}
+	c.mu.RUnlock()
+
+	return clone
+}
+
+// PollUntilComplete polls the location of an asynchronous operation (returned in an
+// AcceptedError) until the server stops answering 202 Accepted, and returns the final result.
+// The poll interval follows the server's Retry-After header, defaulting to DefaultPollInterval.
+// No current endpoint is asynchronous; endpoints that are will be documented here.
+func (c *Client) PollUntilComplete(ctx context.Context, location string) (map[string]interface{}, error) {
+	base, err := c.buildURL("")
 	if err != nil {
 		return nil, err
 	}
 
+	for {
+		pollURL, ok := resolveSameHost(base, location)
+		if !ok {
+			return nil, fmt.Errorf("refusing to poll %q: not on the API host", location)
+		}
+
+		var result map[string]interface{}
+		err := c.makeRequestURL(ctx, "GET", pollURL.Path, pollURL, nil, &result)
+
+		var accepted *AcceptedError
+		if !errors.As(err, &accepted) {
+			if err != nil {
+				return nil, err
+			}
+			if result == nil {
+				result = make(map[string]interface{})
+			}
+			return result, nil
+		}
+
+		location = accepted.Location
+		wait := accepted.RetryAfter
+		if wait <= 0 {
+			wait = DefaultPollInterval
+		}
+		if err := sleepWithClock(ctx, c.getClock(), wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// isIdempotentMethod reports whether repeating a request with method has the same effect as
+// sending it once, which makes it safe to retry after an ambiguous failure
+func isIdempotentMethod(method string) bool {
+	switch strings.ToUpper(method) {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// isRetryableError reports whether a failed request attempt is worth retrying
+func (c *Client) isRetryableError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if c.RetryableErrorFunc != nil {
+		return c.RetryableErrorFunc(err)
+	}
+	return DefaultRetryableError(err)
+}
+
+// DefaultRetryableError is the default retry classification: rate limits (429), server
+// errors (5xx), timeouts, connection resets/refusals and unexpected connection closes are
+// retried; DNS failures, client errors and everything else are not.
+func DefaultRetryableError(err error) bool {
+	var rateLimitErr *RateLimitError
+	var serverErr *ServerError
+	if errors.As(err, &rateLimitErr) || errors.As(err, &serverErr) {
+		return true
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF)
+}
+
+// WithAPIKey returns a copy of the client that authenticates with a different API key.
+// The copy shares the HTTPClient (and its transport) and all other settings with the original,
+// but starts with an empty location tree cache, since the locations visible to each key may differ.
+func (c *Client) WithAPIKey(apiKey string) *Client {
+	derived := c.Clone()
+	derived.APIKey = apiKey
+	derived.HTTPClient = c.HTTPClient
+	return derived
+}
+
+// Ping verifies that the API is reachable and the API key is accepted
+func (c *Client) Ping(ctx context.Context) error {
+	return c.makeRequestInto(ctx, "GET", EndpointUserInfo, nil, nil, nil)
+}
+
+// SetAPIKey replaces the client's API key after confirming the new key works.
+// The new key is verified with Ping before it is swapped in, so requests running
+// concurrently never see an invalid key; on failure the current key is kept and
+// the verification error is returned.
+func (c *Client) SetAPIKey(ctx context.Context, newKey string) error {
+	if newKey == "" {
+		return fmt.Errorf("API key must not be empty")
+	}
+
+	if err := c.WithAPIKey(newKey).Ping(ctx); err != nil {
+		return fmt.Errorf("new API key rejected, keeping the current key: %w", err)
+	}
+
+	c.mu.Lock()
+	c.APIKey = newKey
+	c.mu.Unlock()
+
+	return nil
+}
+
+// SetBaseURL changes the API base URL; safe to call while requests are in flight. The URL is
+// validated like in NewClient when the next request is made.
+func (c *Client) SetBaseURL(baseURL string) {
+	c.mu.Lock()
+	c.BaseURL = strings.TrimRight(baseURL, "/")
+	c.mu.Unlock()
+}
+
+// SetProxyHost changes the proxy gateway host used for new proxy configurations
+func (c *Client) SetProxyHost(host string) {
+	c.mu.Lock()
+	c.ProxyHost = host
+	c.mu.Unlock()
+}
+
+// SetPorts changes the proxy gateway ports used for new proxy configurations
+func (c *Client) SetPorts(httpPort, socks5Port int) {
+	c.mu.Lock()
+	c.HTTPPort = httpPort
+	c.SOCKS5Port = socks5Port
+	c.mu.Unlock()
+}
+
+// clientSettings is a consistent snapshot of the client's mutable connection settings
+type clientSettings struct {
+	apiKey     string
+	baseURL    string
+	proxyHost  string
+	httpPort   int
+	socks5Port int
+}
+
+// settings returns a snapshot of the connection settings
+func (c *Client) settings() clientSettings {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return clientSettings{
+		apiKey:     c.APIKey,
+		baseURL:    c.BaseURL,
+		proxyHost:  c.ProxyHost,
+		httpPort:   c.HTTPPort,
+		socks5Port: c.SOCKS5Port,
+	}
+}
+
+// LastRequestID returns the X-Request-ID sent with the most recent API call, for
+// correlating it with server logs
+func (c *Client) LastRequestID() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.lastRequestID
+}
+
+// getClock returns the client's time source
+func (c *Client) getClock() clock {
+	if c.clock == nil {
+		return systemClock
+	}
+	return c.clock
+}
+
+// apiKey returns the current API key
+func (c *Client) apiKey() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.APIKey
+}
+
+// buildURL resolves an API endpoint against BaseURL, preserving any path prefix
+// (e.g. a gateway mounted at https://internal/nm)
+func (c *Client) buildURL(endpoint string) (*url.URL, error) {
+	baseURL := c.settings().baseURL
+	if err := validateBaseURL(baseURL); err != nil {
+		return nil, err
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	ref, err := url.Parse(strings.TrimPrefix(endpoint, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	// Resolve relative to the base path as a directory so the prefix is kept
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+		u.RawPath = ""
+	}
+	return u.ResolveReference(ref), nil
+}
+
+// GetUserInfo retrieves current user information including proxy credentials and usage data.
+// If the API reports the account as inactive, the user info is returned together with an
+// *AccountSuspendedError.
+func (c *Client) GetUserInfo(ctx context.Context) (*UserInfo, error) {
 	userInfo := &UserInfo{}
-	if err := mapToStruct(result, userInfo); err != nil {
-		return nil, fmt.Errorf("failed to parse user info: %w", err)
+	if err := c.makeRequestInto(ctx, "GET", EndpointUserInfo, nil, nil, userInfo); err != nil {
+		return nil, err
+	}
+	if userInfo.inactive {
+		return userInfo, &AccountSuspendedError{NodeMavenError: &NodeMavenError{
+			StatusCode: http.StatusOK,
+			Message:    "the account is not active",
+		}}
 	}
 
 	return userInfo, nil
@@ -200,13 +910,17 @@ func (c *Client) GetUserInfo(ctx context.Context) (*UserInfo, error) {
 // GetCountries retrieves list of available countries for proxy connections
 func (c *Client) GetCountries(ctx context.Context, req *CountriesRequest) (*CountriesResponse, error) {
 	if req == nil {
-		req = &CountriesRequest{Limit: 50, Offset: 0, ConnectionType: "residential"}
+		req = &CountriesRequest{Limit: 50, Offset: 0}
+	}
+	connectionType := req.ConnectionType
+	if connectionType == "" {
+		connectionType = c.defaultConnectionType()
 	}
 
 	params := map[string]string{
 		"limit":           strconv.Itoa(req.Limit),
 		"offset":          strconv.Itoa(req.Offset),
-		"connection_type": req.ConnectionType,
+		"connection_type": connectionType,
 	}
 	if req.Name != "" {
 		params["name"] = req.Name
@@ -215,14 +929,9 @@ func (c *Client) GetCountries(ctx context.Context, req *CountriesRequest) (*Coun
 		params["code"] = req.Code
 	}
 
-	result, err := c.makeRequest(ctx, "GET", "/api/v2/base/locations/countries/", params, nil)
-	if err != nil {
-		return nil, err
-	}
-
 	response := &CountriesResponse{}
-	if err := mapToStruct(result, response); err != nil {
-		return nil, fmt.Errorf("failed to parse countries response: %w", err)
+	if err := c.makeRequestInto(ctx, "GET", EndpointCountries, params, nil, response); err != nil {
+		return nil, err
 	}
 
 	return response, nil
@@ -231,13 +940,17 @@ func (c *Client) GetCountries(ctx context.Context, req *CountriesRequest) (*Coun
 // GetRegions retrieves list of regions in specified countries
 func (c *Client) GetRegions(ctx context.Context, req *RegionsRequest) (*RegionsResponse, error) {
 	if req == nil {
-		req = &RegionsRequest{Limit: 50, Offset: 0, ConnectionType: "residential"}
+		req = &RegionsRequest{Limit: 50, Offset: 0}
+	}
+	connectionType := req.ConnectionType
+	if connectionType == "" {
+		connectionType = c.defaultConnectionType()
 	}
 
 	params := map[string]string{
 		"limit":           strconv.Itoa(req.Limit),
 		"offset":          strconv.Itoa(req.Offset),
-		"connection_type": req.ConnectionType,
+		"connection_type": connectionType,
 	}
 	if req.CountryCode != "" {
 		params["country__code"] = req.CountryCode
@@ -249,14 +962,9 @@ func (c *Client) GetRegions(ctx context.Context, req *RegionsRequest) (*RegionsR
 		params["code"] = req.Code
 	}
 
-	result, err := c.makeRequest(ctx, "GET", "/api/v2/base/locations/regions/", params, nil)
-	if err != nil {
-		return nil, err
-	}
-
 	response := &RegionsResponse{}
-	if err := mapToStruct(result, response); err != nil {
-		return nil, fmt.Errorf("failed to parse regions response: %w", err)
+	if err := c.makeRequestInto(ctx, "GET", EndpointRegions, params, nil, response); err != nil {
+		return nil, err
 	}
 
 	return response, nil
@@ -265,13 +973,17 @@ func (c *Client) GetRegions(ctx context.Context, req *RegionsRequest) (*RegionsR
 // GetCities retrieves list of cities in specified regions/countries
 func (c *Client) GetCities(ctx context.Context, req *CitiesRequest) (*CitiesResponse, error) {
 	if req == nil {
-		req = &CitiesRequest{Limit: 50, Offset: 0, ConnectionType: "residential"}
+		req = &CitiesRequest{Limit: 50, Offset: 0}
+	}
+	connectionType := req.ConnectionType
+	if connectionType == "" {
+		connectionType = c.defaultConnectionType()
 	}
 
 	params := map[string]string{
 		"limit":           strconv.Itoa(req.Limit),
 		"offset":          strconv.Itoa(req.Offset),
-		"connection_type": req.ConnectionType,
+		"connection_type": connectionType,
 	}
 	if req.CountryCode != "" {
 		params["country__code"] = req.CountryCode
@@ -286,14 +998,9 @@ func (c *Client) GetCities(ctx context.Context, req *CitiesRequest) (*CitiesResp
 		params["code"] = req.Code
 	}
 
-	result, err := c.makeRequest(ctx, "GET", "/api/v2/base/locations/cities/", params, nil)
-	if err != nil {
-		return nil, err
-	}
-
 	response := &CitiesResponse{}
-	if err := mapToStruct(result, response); err != nil {
-		return nil, fmt.Errorf("failed to parse cities response: %w", err)
+	if err := c.makeRequestInto(ctx, "GET", EndpointCities, params, nil, response); err != nil {
+		return nil, err
 	}
 
 	return response, nil
@@ -314,68 +1021,590 @@ func (c *Client) GetStatistics(ctx context.Context, req *StatisticsRequest) (*St
 	if req.EndDate != "" {
 		params["end_date"] = req.EndDate
 	}
+	if req.Country != "" {
+		params["country"] = strings.ToLower(req.Country)
+	}
+	if req.Limit > 0 {
+		params["limit"] = strconv.Itoa(req.Limit)
+	}
+	if req.Offset > 0 {
+		params["offset"] = strconv.Itoa(req.Offset)
+	}
+
+	response := &StatisticsResponse{}
+	if err := c.makeRequestInto(ctx, "GET", EndpointStatistics, params, nil, response); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// statisticsDateFormat is the dd-mm-yyyy layout the statistics endpoint expects
+const statisticsDateFormat = "02-01-2006"
+
+// StatisticsLast24h returns statistics covering the last 24 hours. The API filters by
+// calendar date, so this spans yesterday and today and may include up to 48 hours of data.
+// Dates are computed in UTC.
+func (c *Client) StatisticsLast24h(ctx context.Context) (*StatisticsResponse, error) {
+	now := c.getClock().Now().UTC()
+	return c.statisticsWindow(ctx, now.Add(-24*time.Hour), now)
+}
+
+// StatisticsLast7Days returns daily statistics for the last 7 calendar days (UTC), today included
+func (c *Client) StatisticsLast7Days(ctx context.Context) (*StatisticsResponse, error) {
+	now := c.getClock().Now().UTC()
+	return c.statisticsWindow(ctx, now.AddDate(0, 0, -6), now)
+}
+
+// StatisticsLast30Days returns daily statistics for the last 30 calendar days (UTC), today included
+func (c *Client) StatisticsLast30Days(ctx context.Context) (*StatisticsResponse, error) {
+	now := c.getClock().Now().UTC()
+	return c.statisticsWindow(ctx, now.AddDate(0, 0, -29), now)
+}
+
+// statisticsWindow fetches daily statistics between two dates, inclusive
+func (c *Client) statisticsWindow(ctx context.Context, start, end time.Time) (*StatisticsResponse, error) {
+	return c.GetStatistics(ctx, &StatisticsRequest{
+		StartDate: start.Format(statisticsDateFormat),
+		EndDate:   end.Format(statisticsDateFormat),
+		GroupBy:   "day",
+	})
+}
+
+// StatisticsByCountry fetches daily statistics for the given window (ending now, dates in UTC)
+// separately for each country, concurrently, and returns them keyed by lowercase country code.
+// Entries the API labels with another country are dropped. If the API ignores the country
+// filter, the results are returned together with ErrCountryFilterIgnored, since each of them
+// then holds the account-wide totals.
+func (c *Client) StatisticsByCountry(ctx context.Context, countries []string, window time.Duration) (map[string]*StatisticsResponse, error) {
+	if window <= 0 {
+		return nil, fmt.Errorf("statistics window must be positive")
+	}
+
+	now := c.getClock().Now().UTC()
+	start, end := now.Add(-window).Format(statisticsDateFormat), now.Format(statisticsDateFormat)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		fetchErr error
+		sem      = make(chan struct{}, SampleConcurrency)
+		results  = make(map[string]*StatisticsResponse, len(countries))
+	)
+
+	// Duplicates are dropped up front; results is only touched under mu once fetches start
+	unique := make([]string, 0, len(countries))
+	seen := make(map[string]bool, len(countries))
+	for _, country := range countries {
+		country = strings.ToLower(country)
+		if !seen[country] {
+			seen[country] = true
+			unique = append(unique, country)
+		}
+	}
+
+	for _, country := range unique {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(country string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			response, err := c.GetStatistics(ctx, &StatisticsRequest{
+				StartDate: start,
+				EndDate:   end,
+				GroupBy:   "day",
+				Country:   country,
+			})
+			if err != nil {
+				errOnce.Do(func() {
+					fetchErr = fmt.Errorf("failed to get statistics for %s: %w", country, err)
+					cancel()
+				})
+				return
+			}
+
+			mu.Lock()
+			results[country] = response
+			mu.Unlock()
+		}(country)
+	}
+	wg.Wait()
+
+	if fetchErr != nil {
+		return nil, fetchErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	labelled := false
+	for country, response := range results {
+		kept := response.Results[:0]
+		for _, entry := range response.Results {
+			if entry.Country != "" {
+				labelled = true
+				if !strings.EqualFold(entry.Country, country) {
+					continue
+				}
+			}
+			kept = append(kept, entry)
+		}
+		response.Results = kept
+	}
+
+	if !labelled && len(results) > 1 && sameStatistics(results) {
+		return results, ErrCountryFilterIgnored
+	}
+	return results, nil
+}
+
+// sameStatistics reports whether every response has identical, non-empty results
+func sameStatistics(results map[string]*StatisticsResponse) bool {
+	var first []StatisticEntry
+	for _, response := range results {
+		if len(response.Results) == 0 {
+			return false
+		}
+		if first == nil {
+			first = response.Results
+			continue
+		}
+		if !reflect.DeepEqual(first, response.Results) {
+			return false
+		}
+	}
+	return true
+}
 
-	result, err := c.makeRequest(ctx, "GET", "/api/v2/base/traffic/statistics/", params, nil)
+// GetAllStatistics retrieves usage statistics across all pages
+func (c *Client) GetAllStatistics(ctx context.Context, req *StatisticsRequest) ([]StatisticEntry, error) {
+	if req == nil {
+		req = &StatisticsRequest{GroupBy: "day"}
+	}
+
+	pageReq := *req
+	if pageReq.Limit <= 0 {
+		pageReq.Limit = defaultPageSize
+	}
+
+	var entries []StatisticEntry
+	err := c.paginate(ctx, EndpointStatistics, pageReq.Offset,
+		func(offset int) (listPage, error) {
+			pageReq.Offset = offset
+			return c.GetStatistics(ctx, &pageReq)
+		},
+		func() listPage { return &StatisticsResponse{} },
+		func(page listPage) error {
+			entries = append(entries, page.(*StatisticsResponse).Results...)
+			return nil
+		},
+	)
 	if err != nil {
+		if req.AllowPartial && len(entries) > 0 {
+			return entries, &PartialResultsError{Entries: entries, Err: err}
+		}
 		return nil, err
 	}
 
-	response := &StatisticsResponse{}
-	if err := mapToStruct(result, response); err != nil {
-		return nil, fmt.Errorf("failed to parse statistics response: %w", err)
+	return entries, nil
+}
+
+// StreamStatisticsNDJSON pages through usage statistics and writes each entry to w as one
+// JSON object per line. If w has a Flush method it is flushed after every entry.
+func (c *Client) StreamStatisticsNDJSON(ctx context.Context, req *StatisticsRequest, w io.Writer) error {
+	if req == nil {
+		req = &StatisticsRequest{GroupBy: "day"}
 	}
 
-	return response, nil
+	pageReq := *req
+	if pageReq.Limit <= 0 {
+		pageReq.Limit = defaultPageSize
+	}
+
+	encoder := json.NewEncoder(w)
+	return c.paginate(ctx, EndpointStatistics, pageReq.Offset,
+		func(offset int) (listPage, error) {
+			pageReq.Offset = offset
+			return c.GetStatistics(ctx, &pageReq)
+		},
+		func() listPage { return &StatisticsResponse{} },
+		func(page listPage) error {
+			for _, entry := range page.(*StatisticsResponse).Results {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				if err := encoder.Encode(entry); err != nil {
+					return fmt.Errorf("failed to write statistics entry: %w", err)
+				}
+				if err := flushWriter(w); err != nil {
+					return fmt.Errorf("failed to flush statistics entry: %w", err)
+				}
+			}
+			return nil
+		},
+	)
+}
+
+// ProjectUsage forecasts traffic usage from the statistics of the last window.
+// ProjectedBytes is the expected usage after another window at the same daily average,
+// and ExhaustionDate is left zero when the plan has no limit or there is no recent traffic.
+func (c *Client) ProjectUsage(ctx context.Context, window time.Duration) (*UsageProjection, error) {
+	if window <= 0 {
+		return nil, fmt.Errorf("window must be positive")
+	}
+
+	userInfo, err := c.GetUserInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := c.getClock().Now()
+	entries, err := c.GetAllStatistics(ctx, &StatisticsRequest{
+		StartDate: now.Add(-window).Format(statisticsDateFormat),
+		EndDate:   now.Format(statisticsDateFormat),
+		GroupBy:   "day",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get statistics: %w", err)
+	}
+
+	var total int64
+	for _, entry := range entries {
+		total += entry.TrafficUsed
+	}
+
+	days := window.Hours() / 24
+	if days < 1 {
+		days = 1
+	}
+	dailyAverage := int64(float64(total) / days)
+
+	projection := &UsageProjection{
+		TrafficUsed:    userInfo.TrafficUsed,
+		TrafficLimit:   userInfo.TrafficLimit,
+		DailyAverage:   dailyAverage,
+		Window:         window,
+		ProjectedBytes: userInfo.TrafficUsed + int64(float64(dailyAverage)*days),
+	}
+
+	if userInfo.TrafficLimit > 0 {
+		projection.RemainingBytes = userInfo.TrafficLimit - userInfo.TrafficUsed
+		if projection.RemainingBytes < 0 {
+			projection.RemainingBytes = 0
+		}
+		projection.WillExceed = projection.ProjectedBytes > userInfo.TrafficLimit
+		if dailyAverage > 0 {
+			daysLeft := float64(projection.RemainingBytes) / float64(dailyAverage)
+			projection.ExhaustionDate = now.Add(time.Duration(daysLeft * float64(24*time.Hour)))
+		}
+	}
+
+	return projection, nil
 }
 
 // GetProxyConfig returns proxy configuration for HTTP/HTTPS usage
 func (c *Client) GetProxyConfig(options *ProxyOptions) (*ProxyConfig, error) {
 	// Get proxy credentials from API
 	ctx := context.Background()
-	userInfo, err := c.GetUserInfo(ctx)
+	userInfo, err := c.proxyCredentials(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get proxy credentials: %w", err)
+		return nil, err
+	}
+
+	return c.newProxyConfig(userInfo, MergeProxyOptions(c.DefaultProxyOptions, options))
+}
+
+// GetProxyConfigWithIP returns proxy configuration like GetProxyConfig and, when verify is
+// set, makes one request through the proxy to return its current exit IP. Without verify
+// the IP is empty and no request is made through the proxy.
+func (c *Client) GetProxyConfigWithIP(ctx context.Context, options *ProxyOptions, verify bool) (*ProxyConfig, string, error) {
+	userInfo, err := c.proxyCredentials(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	proxyConfig, err := c.newProxyConfig(userInfo, MergeProxyOptions(c.DefaultProxyOptions, options))
+	if err != nil {
+		return nil, "", err
+	}
+	if !verify {
+		return proxyConfig, "", nil
 	}
 
-	if userInfo.ProxyUsername == "" || userInfo.ProxyPassword == "" {
-		return nil, fmt.Errorf("proxy credentials not available")
+	// Verify over a throwaway transport so the probe leaves no idle connection behind
+	transport := proxyConfig.Transport()
+	defer transport.CloseIdleConnections()
+
+	ip, err := GetCurrentIPContext(ctx, &http.Client{Transport: transport, Timeout: c.Timeout})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to verify proxy exit IP: %w", err)
+	}
+	return proxyConfig, ip, nil
+}
+
+// proxyCredentials fetches the account's proxy credentials. Responses with empty credentials,
+// which occur briefly while an account is provisioned, are retried up to CredentialRetries times.
+func (c *Client) proxyCredentials(ctx context.Context) (*UserInfo, error) {
+	var userInfo *UserInfo
+	for attempt := 0; ; attempt++ {
+		var err error
+		userInfo, err = c.GetUserInfo(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get proxy credentials: %w", err)
+		}
+		if userInfo.ProxyUsername != "" && userInfo.ProxyPassword != "" {
+			break
+		}
+		if attempt >= c.CredentialRetries {
+			return nil, &CredentialsError{Reason: "proxy credentials not available. Check that your plan includes proxy access"}
+		}
+
+		backoff := c.Backoff
+		if backoff == nil {
+			backoff = DefaultBackoff()
+		}
+		if err := sleepWithClock(ctx, c.getClock(), backoff.Next(attempt)); err != nil {
+			return nil, err
+		}
+	}
+	return userInfo, nil
+}
+
+// gateway resolves the proxy gateway endpoint for an account. Precedence per field:
+// a host or port explicitly configured on the client (anything other than the package
+// default) wins, then the account-specific gateway reported by the API, then the default.
+func (c *Client) gateway(userInfo *UserInfo) clientSettings {
+	settings := c.settings()
+	if settings.proxyHost == DefaultProxyHost && userInfo.ProxyHost != "" {
+		settings.proxyHost = userInfo.ProxyHost
+	}
+	if settings.httpPort == DefaultHTTPPort {
+		settings.httpPort = userInfo.portFor("http", settings.httpPort)
+	}
+	if settings.socks5Port == DefaultSOCKS5Port {
+		settings.socks5Port = userInfo.portFor("socks5", settings.socks5Port)
+	}
+	return settings
+}
+
+// newProxyConfig builds a proxy configuration from already fetched credentials
+func (c *Client) newProxyConfig(userInfo *UserInfo, options *ProxyOptions) (*ProxyConfig, error) {
+	if options != nil && options.AutoSession && options.Session == "" {
+		options = copyProxyOptions(options)
+		options.Session = GenerateSessionID()
+	}
+	if c.DefaultConnectionType != "" && (options == nil || options.ConnectionType == "") {
+		if options == nil {
+			options = &ProxyOptions{}
+		} else {
+			options = copyProxyOptions(options)
+		}
+		options.ConnectionType = c.DefaultConnectionType
 	}
 
 	// Build proxy username with targeting
-	username := buildProxyUsername(userInfo.ProxyUsername, options)
+	username, err := c.buildUsername(userInfo.ProxyUsername, options)
+	if err != nil {
+		return nil, err
+	}
 
+	gateway := c.gateway(userInfo)
 	return &ProxyConfig{
-		Host:     c.ProxyHost,
-		HTTPPort: c.HTTPPort,
-		Username: username,
-		Password: userInfo.ProxyPassword,
-		client:   c,
-		options:  options,
+		Host:        gateway.proxyHost,
+		HTTPPort:    gateway.httpPort,
+		SOCKS5Port:  gateway.socks5Port,
+		Username:    username,
+		Password:    userInfo.ProxyPassword,
+		DialTimeout: c.DialTimeout,
+		client:      c,
+		options:     options,
 	}, nil
 }
 
-// GetSOCKS5ProxyURL returns SOCKS5 proxy URL with targeting parameters
+// SampleIPs opens n fresh sticky sessions with the given targeting, requests each session's
+// exit IP concurrently and returns the distinct IPs observed. Use SampleIPCounts to see
+// how often each IP was repeated.
+func (c *Client) SampleIPs(ctx context.Context, options *ProxyOptions, n int) ([]string, error) {
+	counts, err := c.SampleIPCounts(ctx, options, n)
+	if err != nil {
+		return nil, err
+	}
+
+	ips := make([]string, 0, len(counts))
+	for ip := range counts {
+		ips = append(ips, ip)
+	}
+	sort.Strings(ips)
+	return ips, nil
+}
+
+// SampleIPCounts is like SampleIPs but returns how many sessions landed on each exit IP.
+// Counts above 1 reveal duplicates, which indicate a small pool for the targeting.
+// At most SampleConcurrency probes run at once; failed probes are skipped, and an error
+// is returned only if every probe failed.
+func (c *Client) SampleIPCounts(ctx context.Context, options *ProxyOptions, n int) (map[string]int, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("sample size must be positive")
+	}
+
+	userInfo, err := c.proxyCredentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+	transport, err := c.sessionTransport(userInfo, MergeProxyOptions(c.DefaultProxyOptions, options))
+	if err != nil {
+		return nil, err
+	}
+	defer transport.CloseIdleConnections()
+	httpClient := &http.Client{Transport: transport, Timeout: c.Timeout}
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		lastErr error
+		counts  = make(map[string]int)
+		sem     = make(chan struct{}, SampleConcurrency)
+	)
+
+	for i := 0; i < n; i++ {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return nil, ctx.Err()
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			sessionCtx := ContextWithProxyOptions(ctx, &ProxyOptions{Session: GenerateSessionID()})
+			ip, err := GetCurrentIPContext(sessionCtx, httpClient)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				lastErr = err
+				return
+			}
+			counts[ip]++
+		}()
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(counts) == 0 && lastErr != nil {
+		return nil, fmt.Errorf("all IP samples failed: %w", lastErr)
+	}
+
+	return counts, nil
+}
+
+// buildUsername builds the proxy username with the client's username format
+func (c *Client) buildUsername(baseUsername string, options *ProxyOptions) (string, error) {
+	if c.UsernameFormat == nil {
+		return buildProxyUsername(baseUsername, options)
+	}
+	return c.UsernameFormat.BuildUsername(baseUsername, options)
+}
+
+// ProbeConnectionTypes checks which connection types work for the account by making
+// a minimal request through the proxy for each of them concurrently
+func (c *Client) ProbeConnectionTypes(ctx context.Context) (map[string]bool, error) {
+	userInfo, err := c.proxyCredentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	transport, err := c.sessionTransport(userInfo, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer transport.CloseIdleConnections()
+	httpClient := &http.Client{Transport: transport, Timeout: c.Timeout}
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results = make(map[string]bool, len(ConnectionTypes))
+	)
+
+	for _, connectionType := range ConnectionTypes {
+		wg.Add(1)
+		go func(connectionType string) {
+			defer wg.Done()
+
+			probeCtx, cancel := context.WithTimeout(ctx, ProbeTimeout)
+			defer cancel()
+
+			probeCtx = ContextWithProxyOptions(probeCtx, &ProxyOptions{ConnectionType: connectionType})
+			_, err := GetCurrentIPContext(probeCtx, httpClient)
+
+			mu.Lock()
+			results[connectionType] = err == nil
+			mu.Unlock()
+		}(connectionType)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return results, err
+	}
+	return results, nil
+}
+
+// GetSOCKS5ProxyURL returns SOCKS5 proxy URL with targeting parameters.
+// The URL uses the socks5h scheme (DNS resolved by the proxy) unless options.LocalDNS is set,
+// in which case the socks5 scheme (DNS resolved locally) is used.
 func (c *Client) GetSOCKS5ProxyURL(options *ProxyOptions) (string, error) {
 	// Get proxy credentials from API
 	ctx := context.Background()
-	userInfo, err := c.GetUserInfo(ctx)
+	userInfo, err := c.proxyCredentials(ctx)
 	if err != nil {
-		return "", fmt.Errorf("failed to get proxy credentials: %w", err)
+		return "", err
 	}
 
-	if userInfo.ProxyUsername == "" || userInfo.ProxyPassword == "" {
-		return "", fmt.Errorf("proxy credentials not available")
+	proxyConfig, err := c.newProxyConfig(userInfo, MergeProxyOptions(c.DefaultProxyOptions, options))
+	if err != nil {
+		return "", err
 	}
 
-	// Build proxy username with targeting
-	username := buildProxyUsername(userInfo.ProxyUsername, options)
-
-	return fmt.Sprintf("socks5://%s:%s@%s:%d",
-		username, userInfo.ProxyPassword, c.ProxyHost, c.SOCKS5Port), nil
+	return proxyConfig.SOCKS5ProxyURL(), nil
 }
 
 // Helper functions
 
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP date relative to now
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(strings.TrimSpace(value)); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := date.Sub(now); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
+
 func getEnvWithDefault(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -424,10 +1653,13 @@ func parseErrorMessage(errorData map[string]interface{}, statusCode int, status
 	return fmt.Sprintf("HTTP %d: %s", statusCode, status)
 }
 
-func mapToStruct(data map[string]interface{}, target interface{}) error {
-	jsonData, err := json.Marshal(data)
-	if err != nil {
-		return err
+// flushWriter flushes writers such as *bufio.Writer or http.Flusher implementations
+func flushWriter(w io.Writer) error {
+	switch f := w.(type) {
+	case interface{ Flush() error }:
+		return f.Flush()
+	case interface{ Flush() }:
+		f.Flush()
 	}
-	return json.Unmarshal(jsonData, target)
+	return nil
 }
@@ -0,0 +1,312 @@
+package nodemaven
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testUserInfo = `{"id": "u1", "email": "user@example.com", "proxy_username": "test_user",
+	"proxy_password": "pw12345678abc", "traffic_used": 0, "traffic_limit": 1000, "is_active": true}`
+
+// newTestClient returns a client talking to an httptest server running handler
+func newTestClient(t *testing.T, handler http.HandlerFunc) (*Client, *httptest.Server) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(&Config{APIKey: "test-key", BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	return client, server
+}
+
+// recordingClock fires every timer at once and records the requested delays
+type recordingClock struct {
+	mu    sync.Mutex
+	waits []time.Duration
+}
+
+func (r *recordingClock) Now() time.Time { return time.Now() }
+
+func (r *recordingClock) After(d time.Duration) <-chan time.Time {
+	r.mu.Lock()
+	r.waits = append(r.waits, d)
+	r.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	ch <- time.Now()
+	return ch
+}
+
+func (r *recordingClock) delays() []time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]time.Duration(nil), r.waits...)
+}
+
+func TestResolveGatewayRegion(t *testing.T) {
+	host, err := resolveGatewayRegion("gw.example.com")
+	if err != nil || host != "gw.example.com" {
+		t.Errorf("resolveGatewayRegion(hostname) = %q, %v; want the hostname", host, err)
+	}
+	if _, err := resolveGatewayRegion("eu"); err == nil {
+		t.Error("resolveGatewayRegion(\"eu\") succeeded without a registered region")
+	}
+}
+
+// TestReconfigureWhileInFlight is meant for -race: setters run while requests read the settings
+func TestReconfigureWhileInFlight(t *testing.T) {
+	client, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(testUserInfo))
+	})
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				if _, err := client.GetUserInfo(ctx); err != nil {
+					t.Errorf("GetUserInfo: %v", err)
+					return
+				}
+				if _, err := client.GetProxyConfig(nil); err != nil {
+					t.Errorf("GetProxyConfig: %v", err)
+					return
+				}
+			}
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for j := 0; j < 20; j++ {
+			client.SetBaseURL(server.URL + "/")
+			client.SetProxyHost("gate.example.com")
+			client.SetPorts(8080+j, 1080+j)
+			if err := client.SetAPIKey(ctx, "rotated-key"); err != nil {
+				t.Errorf("SetAPIKey: %v", err)
+				return
+			}
+		}
+	}()
+	wg.Wait()
+
+	if settings := client.settings(); settings.apiKey != "rotated-key" || settings.baseURL != server.URL {
+		t.Errorf("settings after reconfiguration = %+v", settings)
+	}
+}
+
+func TestBuildURL(t *testing.T) {
+	tests := []struct {
+		baseURL  string
+		endpoint string
+		want     string
+	}{
+		{"https://api.example.com", EndpointUserInfo, "https://api.example.com/api/v2/base/users/me"},
+		{"https://api.example.com/", EndpointUserInfo, "https://api.example.com/api/v2/base/users/me"},
+		{"https://internal.example.com/nm", EndpointCountries, "https://internal.example.com/nm/api/v2/base/locations/countries/"},
+		{"https://internal.example.com/nm/", EndpointCountries, "https://internal.example.com/nm/api/v2/base/locations/countries/"},
+		{"https://internal.example.com/a/b", "api/v2/base/users/me", "https://internal.example.com/a/b/api/v2/base/users/me"},
+	}
+	for _, tt := range tests {
+		client := &Client{BaseURL: tt.baseURL}
+		got, err := client.buildURL(tt.endpoint)
+		if err != nil {
+			t.Errorf("buildURL(%q, %q): %v", tt.baseURL, tt.endpoint, err)
+			continue
+		}
+		if got.String() != tt.want {
+			t.Errorf("buildURL(%q, %q) = %q, want %q", tt.baseURL, tt.endpoint, got, tt.want)
+		}
+	}
+}
+
+func TestTrailingSlashBaseURL(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		paths []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.RequestURI())
+		mu.Unlock()
+		w.Write([]byte(testUserInfo))
+	}))
+	defer server.Close()
+
+	for _, baseURL := range []string{server.URL, server.URL + "/"} {
+		client, err := NewClient(&Config{APIKey: "test-key", BaseURL: baseURL})
+		if err != nil {
+			t.Fatalf("NewClient(%q): %v", baseURL, err)
+		}
+		if _, err := client.GetUserInfo(context.Background()); err != nil {
+			t.Fatalf("GetUserInfo with base %q: %v", baseURL, err)
+		}
+	}
+
+	if len(paths) != 2 || paths[0] != paths[1] || paths[0] != EndpointUserInfo {
+		t.Errorf("requested paths = %q, want %q twice", paths, EndpointUserInfo)
+	}
+}
+
+// countriesPage returns a countries response body with n results
+func countriesPage(n int) []byte {
+	results := make([]string, n)
+	for i := range results {
+		results[i] = fmt.Sprintf(`{"id": "%d", "name": "Country %d", "code": "c%d", "connection_type": "residential",
+			"regions_count": 12, "cities_count": 340, "proxies_count": 15000}`, i, i, i)
+	}
+	return []byte(fmt.Sprintf(`{"count": %d, "next": null, "previous": null, "results": [%s]}`, n, strings.Join(results, ",")))
+}
+
+// BenchmarkDecodeDirect decodes a response straight into its struct, as the typed methods do
+func BenchmarkDecodeDirect(b *testing.B) {
+	body := countriesPage(250)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var page CountriesResponse
+		if err := json.Unmarshal(body, &page); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkDecodeViaMap decodes a response into a map and re-marshals it into the struct,
+// as the typed methods did before decoding directly
+func BenchmarkDecodeViaMap(b *testing.B) {
+	body := countriesPage(250)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var data map[string]interface{}
+		if err := json.Unmarshal(body, &data); err != nil {
+			b.Fatal(err)
+		}
+		remarshaled, err := json.Marshal(data)
+		if err != nil {
+			b.Fatal(err)
+		}
+		var page CountriesResponse
+		if err := json.Unmarshal(remarshaled, &page); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGetCountries(b *testing.B) {
+	body := countriesPage(250)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(body)
+	}))
+	defer server.Close()
+	client, err := NewClient(&Config{APIKey: "test-key", BaseURL: server.URL})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := client.GetCountries(context.Background(), nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestValidateBaseURL(t *testing.T) {
+	valid := []string{
+		"https://api.example.com",
+		"https://internal.example.com/nm",
+		"http://localhost:8080",
+		"http://127.0.0.1:9000",
+		"http://[::1]:9000",
+	}
+	for _, baseURL := range valid {
+		if err := validateBaseURL(baseURL); err != nil {
+			t.Errorf("validateBaseURL(%q): %v", baseURL, err)
+		}
+	}
+
+	invalid := []string{
+		"",
+		"api.example.com",
+		"http://api.example.com",
+		"ftp://api.example.com",
+		"ws://localhost:8080",
+		"https://",
+		"://missing-scheme",
+	}
+	for _, baseURL := range invalid {
+		if err := validateBaseURL(baseURL); err == nil {
+			t.Errorf("validateBaseURL(%q) succeeded, want an error", baseURL)
+		}
+	}
+
+	if _, err := NewClient(&Config{APIKey: "test-key", BaseURL: "http://api.example.com"}); err == nil {
+		t.Error("NewClient accepted a plain http base URL for a remote host")
+	}
+}
+
+func TestWithAPIKeyLocationTreeCache(t *testing.T) {
+	client, err := NewClient(&Config{APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	client.storeLocationTree(&LocationTree{ConnectionType: "residential"})
+
+	derived := client.WithAPIKey("other-key")
+	if derived.cachedLocationTree("residential") != nil {
+		t.Error("WithAPIKey copy sees the location tree cached under the original key")
+	}
+	derived.storeLocationTree(&LocationTree{ConnectionType: "mobile"})
+	if client.cachedLocationTree("mobile") != nil {
+		t.Error("original client sees the location tree cached by the WithAPIKey copy")
+	}
+}
+
+// TestStatisticsByCountry is meant for -race: several countries, one repeated, fetched concurrently
+func TestStatisticsByCountry(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests = map[string]int{}
+	)
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		country := r.URL.Query().Get("country")
+		mu.Lock()
+		requests[country]++
+		mu.Unlock()
+		fmt.Fprintf(w, `{"count": 1, "next": null, "previous": null, "results": [
+			{"date": "2026-10-01", "country": %q, "traffic_used": %d, "requests": 3, "success_rate": 99.5}]}`,
+			country, len(country)*100)
+	})
+
+	countries := []string{"us", "DE", "fr", "de", "gb", "US", "jp"}
+	results, err := client.StatisticsByCountry(context.Background(), countries, 72*time.Hour)
+	if err != nil {
+		t.Fatalf("StatisticsByCountry: %v", err)
+	}
+
+	want := []string{"us", "de", "fr", "gb", "jp"}
+	if len(results) != len(want) {
+		t.Errorf("got %d countries, want %d", len(results), len(want))
+	}
+	for _, country := range want {
+		response := results[country]
+		if response == nil || len(response.Results) != 1 || response.Results[0].Country != country {
+			t.Errorf("results[%q] = %+v", country, response)
+		}
+		if requests[country] != 1 {
+			t.Errorf("statistics for %q fetched %d times, want 1", country, requests[country])
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package nodemaven
+
+import (
+	"context"
+	"time"
+)
+
+// clock abstracts time so time-dependent features (backoff, polling, session age,
+// cache timestamps) can be tested deterministically
+type clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// realClock is the clock backed by the time package
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// systemClock is the clock used when none is injected
+var systemClock clock = realClock{}
+
+// sleepWithClock waits for d on the given clock or until the context is done
+func sleepWithClock(ctx context.Context, clk clock, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-clk.After(d):
+		return nil
+	}
+}
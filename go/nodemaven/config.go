@@ -0,0 +1,108 @@
+package nodemaven
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// fileConfig is the JSON representation of Config
+type fileConfig struct {
+	APIKey                string                   `json:"api_key"`
+	BaseURL               string                   `json:"base_url"`
+	ProxyHost             string                   `json:"proxy_host"`
+	GatewayRegion         string                   `json:"gateway_region"`
+	DefaultConnectionType string                   `json:"default_connection_type"`
+	HTTPPort              int                      `json:"http_port"`
+	SOCKS5Port            int                      `json:"socks5_port"`
+	Timeout               string                   `json:"timeout"`
+	DefaultProxyOptions   *ProxyOptions            `json:"default_proxy_options"`
+	Profiles              map[string]*ProxyOptions `json:"profiles"`
+}
+
+// ConfigFromJSON reads client configuration from a JSON file, e.g.
+//
+//	{"api_key": "...", "proxy_host": "gate.nodemaven.com", "timeout": "45s",
+//	 "profiles": {"us-high": {"country": "us"}}}
+//
+// Timeout accepts a duration string such as "30s" or "1m". Values from the file take
+// precedence over environment variables, which NewClient only consults for fields left
+// unset; call ApplyEnv on the result to let environment variables override the file.
+func ConfigFromJSON(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var fc fileConfig
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&fc); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	config := &Config{
+		APIKey:                fc.APIKey,
+		BaseURL:               fc.BaseURL,
+		ProxyHost:             fc.ProxyHost,
+		GatewayRegion:         fc.GatewayRegion,
+		DefaultConnectionType: fc.DefaultConnectionType,
+		HTTPPort:              fc.HTTPPort,
+		SOCKS5Port:            fc.SOCKS5Port,
+		DefaultProxyOptions:   fc.DefaultProxyOptions,
+		Profiles:              fc.Profiles,
+	}
+
+	if fc.Timeout != "" {
+		timeout, err := time.ParseDuration(fc.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout %q in config file: %w", fc.Timeout, err)
+		}
+		config.Timeout = timeout
+	}
+
+	if err := config.validate(); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	return config, nil
+}
+
+// ApplyEnv overrides config fields with any NodeMaven environment variables that are set.
+// NODEMAVEN_PROXY_HOST names the gateway explicitly, so it also clears GatewayRegion.
+func (c *Config) ApplyEnv() {
+	if value := os.Getenv("NODEMAVEN_APIKEY"); value != "" {
+		c.APIKey = value
+	}
+	if value := os.Getenv("NODEMAVEN_BASE_URL"); value != "" {
+		c.BaseURL = value
+	}
+	if value := os.Getenv("NODEMAVEN_PROXY_HOST"); value != "" {
+		c.ProxyHost = value
+		c.GatewayRegion = ""
+	}
+	c.HTTPPort = getEnvIntWithDefault("NODEMAVEN_HTTP_PORT", c.HTTPPort)
+	c.SOCKS5Port = getEnvIntWithDefault("NODEMAVEN_SOCKS5_PORT", c.SOCKS5Port)
+	if timeoutSecs := getEnvIntWithDefault("REQUEST_TIMEOUT", 0); timeoutSecs > 0 {
+		c.Timeout = time.Duration(timeoutSecs) * time.Second
+	}
+}
+
+// validate checks that required fields are present and values are in range
+func (c *Config) validate() error {
+	if c.APIKey == "" && os.Getenv("NODEMAVEN_APIKEY") == "" {
+		return fmt.Errorf("api_key is required unless NODEMAVEN_APIKEY is set")
+	}
+	if c.HTTPPort < 0 || c.HTTPPort > 65535 {
+		return fmt.Errorf("http_port %d is out of range", c.HTTPPort)
+	}
+	if c.SOCKS5Port < 0 || c.SOCKS5Port > 65535 {
+		return fmt.Errorf("socks5_port %d is out of range", c.SOCKS5Port)
+	}
+	if c.Timeout < 0 {
+		return fmt.Errorf("timeout must not be negative")
+	}
+	return nil
+}
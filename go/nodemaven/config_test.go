@@ -0,0 +1,43 @@
+package nodemaven
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestApplyEnvProxyHostOverridesGatewayRegion(t *testing.T) {
+	GatewayRegions["test-region"] = "region.gate.example.com"
+	defer delete(GatewayRegions, "test-region")
+
+	path := filepath.Join(t.TempDir(), "nodemaven.json")
+	if err := os.WriteFile(path, []byte(`{"api_key": "file-key", "gateway_region": "test-region"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := ConfigFromJSON(path)
+	if err != nil {
+		t.Fatalf("ConfigFromJSON: %v", err)
+	}
+	t.Setenv("NODEMAVEN_PROXY_HOST", "env.gate.example.com")
+	config.ApplyEnv()
+
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("NewClient after ApplyEnv: %v", err)
+	}
+	if client.ProxyHost != "env.gate.example.com" {
+		t.Errorf("ProxyHost = %q, want the environment's", client.ProxyHost)
+	}
+
+	// Without the variable the file's region is kept
+	os.Unsetenv("NODEMAVEN_PROXY_HOST")
+	config, err = ConfigFromJSON(path)
+	if err != nil {
+		t.Fatalf("ConfigFromJSON: %v", err)
+	}
+	config.ApplyEnv()
+	if client, err = NewClient(config); err != nil || client.ProxyHost != "region.gate.example.com" {
+		t.Errorf("NewClient without the variable: ProxyHost = %q, %v; want the region's gateway", client.ProxyHost, err)
+	}
+}
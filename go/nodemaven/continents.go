@@ -0,0 +1,108 @@
+package nodemaven
+
+// Continent names used by GroupCountriesByContinent
+const (
+	continentAfrica       = "Africa"
+	continentAntarctica   = "Antarctica"
+	continentAsia         = "Asia"
+	continentEurope       = "Europe"
+	continentNorthAmerica = "North America"
+	continentOceania      = "Oceania"
+	continentSouthAmerica = "South America"
+	continentUnknown      = "Unknown"
+)
+
+// countryContinents maps ISO 3166-1 alpha-2 country codes to their continent
+var countryContinents = map[string]string{
+	// Africa
+	"AO": continentAfrica, "BF": continentAfrica, "BI": continentAfrica, "BJ": continentAfrica,
+	"BW": continentAfrica, "CD": continentAfrica, "CF": continentAfrica, "CG": continentAfrica,
+	"CI": continentAfrica, "CM": continentAfrica, "CV": continentAfrica, "DJ": continentAfrica,
+	"DZ": continentAfrica, "EG": continentAfrica, "EH": continentAfrica, "ER": continentAfrica,
+	"ET": continentAfrica, "GA": continentAfrica, "GH": continentAfrica, "GM": continentAfrica,
+	"GN": continentAfrica, "GQ": continentAfrica, "GW": continentAfrica, "KE": continentAfrica,
+	"KM": continentAfrica, "LR": continentAfrica, "LS": continentAfrica, "LY": continentAfrica,
+	"MA": continentAfrica, "MG": continentAfrica, "ML": continentAfrica, "MR": continentAfrica,
+	"MU": continentAfrica, "MW": continentAfrica, "MZ": continentAfrica, "NA": continentAfrica,
+	"NE": continentAfrica, "NG": continentAfrica, "RE": continentAfrica, "RW": continentAfrica,
+	"SC": continentAfrica, "SD": continentAfrica, "SH": continentAfrica, "SL": continentAfrica,
+	"SN": continentAfrica, "SO": continentAfrica, "SS": continentAfrica, "ST": continentAfrica,
+	"SZ": continentAfrica, "TD": continentAfrica, "TG": continentAfrica, "TN": continentAfrica,
+	"TZ": continentAfrica, "UG": continentAfrica, "YT": continentAfrica, "ZA": continentAfrica,
+	"ZM": continentAfrica, "ZW": continentAfrica,
+	// Antarctica
+	"AQ": continentAntarctica, "BV": continentAntarctica, "GS": continentAntarctica, "HM": continentAntarctica,
+	"TF": continentAntarctica,
+	// Asia
+	"AE": continentAsia, "AF": continentAsia, "AM": continentAsia, "AZ": continentAsia,
+	"BD": continentAsia, "BH": continentAsia, "BN": continentAsia, "BT": continentAsia,
+	"CN": continentAsia, "CY": continentAsia, "GE": continentAsia, "HK": continentAsia,
+	"ID": continentAsia, "IL": continentAsia, "IN": continentAsia, "IO": continentAsia,
+	"IQ": continentAsia, "IR": continentAsia, "JO": continentAsia, "JP": continentAsia,
+	"KG": continentAsia, "KH": continentAsia, "KP": continentAsia, "KR": continentAsia,
+	"KW": continentAsia, "KZ": continentAsia, "LA": continentAsia, "LB": continentAsia,
+	"LK": continentAsia, "MM": continentAsia, "MN": continentAsia, "MO": continentAsia,
+	"MV": continentAsia, "MY": continentAsia, "NP": continentAsia, "OM": continentAsia,
+	"PH": continentAsia, "PK": continentAsia, "PS": continentAsia, "QA": continentAsia,
+	"SA": continentAsia, "SG": continentAsia, "SY": continentAsia, "TH": continentAsia,
+	"TJ": continentAsia, "TL": continentAsia, "TM": continentAsia, "TR": continentAsia,
+	"TW": continentAsia, "UZ": continentAsia, "VN": continentAsia, "YE": continentAsia,
+	// Europe
+	"AD": continentEurope, "AL": continentEurope, "AT": continentEurope, "AX": continentEurope,
+	"BA": continentEurope, "BE": continentEurope, "BG": continentEurope, "BY": continentEurope,
+	"CH": continentEurope, "CZ": continentEurope, "DE": continentEurope, "DK": continentEurope,
+	"EE": continentEurope, "ES": continentEurope, "FI": continentEurope, "FO": continentEurope,
+	"FR": continentEurope, "GB": continentEurope, "GG": continentEurope, "GI": continentEurope,
+	"GR": continentEurope, "HR": continentEurope, "HU": continentEurope, "IE": continentEurope,
+	"IM": continentEurope, "IS": continentEurope, "IT": continentEurope, "JE": continentEurope,
+	"LI": continentEurope, "LT": continentEurope, "LU": continentEurope, "LV": continentEurope,
+	"MC": continentEurope, "MD": continentEurope, "ME": continentEurope, "MK": continentEurope,
+	"MT": continentEurope, "NL": continentEurope, "NO": continentEurope, "PL": continentEurope,
+	"PT": continentEurope, "RO": continentEurope, "RS": continentEurope, "RU": continentEurope,
+	"SE": continentEurope, "SI": continentEurope, "SJ": continentEurope, "SK": continentEurope,
+	"SM": continentEurope, "UA": continentEurope, "VA": continentEurope, "XK": continentEurope,
+	// North America
+	"AG": continentNorthAmerica, "AI": continentNorthAmerica, "AW": continentNorthAmerica, "BB": continentNorthAmerica,
+	"BL": continentNorthAmerica, "BM": continentNorthAmerica, "BQ": continentNorthAmerica, "BS": continentNorthAmerica,
+	"BZ": continentNorthAmerica, "CA": continentNorthAmerica, "CR": continentNorthAmerica, "CU": continentNorthAmerica,
+	"CW": continentNorthAmerica, "DM": continentNorthAmerica, "DO": continentNorthAmerica, "GD": continentNorthAmerica,
+	"GL": continentNorthAmerica, "GP": continentNorthAmerica, "GT": continentNorthAmerica, "HN": continentNorthAmerica,
+	"HT": continentNorthAmerica, "JM": continentNorthAmerica, "KN": continentNorthAmerica, "KY": continentNorthAmerica,
+	"LC": continentNorthAmerica, "MF": continentNorthAmerica, "MQ": continentNorthAmerica, "MS": continentNorthAmerica,
+	"MX": continentNorthAmerica, "NI": continentNorthAmerica, "PA": continentNorthAmerica, "PM": continentNorthAmerica,
+	"PR": continentNorthAmerica, "SV": continentNorthAmerica, "SX": continentNorthAmerica, "TC": continentNorthAmerica,
+	"TT": continentNorthAmerica, "UM": continentNorthAmerica, "US": continentNorthAmerica, "VC": continentNorthAmerica,
+	"VG": continentNorthAmerica, "VI": continentNorthAmerica,
+	// Oceania
+	"AS": continentOceania, "AU": continentOceania, "CC": continentOceania, "CK": continentOceania,
+	"CX": continentOceania, "FJ": continentOceania, "FM": continentOceania, "GU": continentOceania,
+	"KI": continentOceania, "MH": continentOceania, "MP": continentOceania, "NC": continentOceania,
+	"NF": continentOceania, "NR": continentOceania, "NU": continentOceania, "NZ": continentOceania,
+	"PF": continentOceania, "PG": continentOceania, "PN": continentOceania, "PW": continentOceania,
+	"SB": continentOceania, "TK": continentOceania, "TO": continentOceania, "TV": continentOceania,
+	"VU": continentOceania, "WF": continentOceania, "WS": continentOceania,
+	// South America
+	"AR": continentSouthAmerica, "BO": continentSouthAmerica, "BR": continentSouthAmerica, "CL": continentSouthAmerica,
+	"CO": continentSouthAmerica, "EC": continentSouthAmerica, "FK": continentSouthAmerica, "GF": continentSouthAmerica,
+	"GY": continentSouthAmerica, "PE": continentSouthAmerica, "PY": continentSouthAmerica, "SR": continentSouthAmerica,
+	"UY": continentSouthAmerica, "VE": continentSouthAmerica,
+}
+
+// ContinentForCountry returns the continent for an ISO country code, or "Unknown"
+func ContinentForCountry(code string) string {
+	if continent, ok := countryContinents[NormalizeCountryCode(code)]; ok {
+		return continent
+	}
+	return continentUnknown
+}
+
+// GroupCountriesByContinent groups countries by continent, preserving the input order within each group.
+// Countries with unrecognized codes are grouped under "Unknown".
+func GroupCountriesByContinent(countries []Country) map[string][]Country {
+	groups := make(map[string][]Country)
+	for _, country := range countries {
+		continent := ContinentForCountry(country.Code)
+		groups[continent] = append(groups[continent], country)
+	}
+	return groups
+}
@@ -0,0 +1,36 @@
+package nodemaven
+
+import (
+	"testing"
+)
+
+func TestContinentForCountry(t *testing.T) {
+	tests := map[string]string{
+		"US":  continentNorthAmerica,
+		"de":  continentEurope,
+		" jp": continentAsia,
+		"BR":  continentSouthAmerica,
+		"NG":  continentAfrica,
+		"AU":  continentOceania,
+		"XX":  continentUnknown,
+		"":    continentUnknown,
+	}
+	for code, want := range tests {
+		if got := ContinentForCountry(code); got != want {
+			t.Errorf("ContinentForCountry(%q) = %q, want %q", code, got, want)
+		}
+	}
+}
+
+func TestGroupCountriesByContinent(t *testing.T) {
+	countries := []Country{{Code: "fr"}, {Code: "us"}, {Code: "de"}, {Code: "zz"}}
+	groups := GroupCountriesByContinent(countries)
+
+	europe := groups[continentEurope]
+	if len(europe) != 2 || europe[0].Code != "fr" || europe[1].Code != "de" {
+		t.Errorf("Europe = %+v, want fr then de", europe)
+	}
+	if len(groups[continentNorthAmerica]) != 1 || len(groups[continentUnknown]) != 1 {
+		t.Errorf("groups = %+v", groups)
+	}
+}
@@ -0,0 +1,52 @@
+package nodemaven
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"regexp"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// secretFieldPattern matches JSON fields whose values must never appear in debug output
+var secretFieldPattern = regexp.MustCompile(`("(?:proxy_password|password|api_key|apikey)"\s*:\s*")[^"]*(")`)
+
+// dumpRequest writes the outgoing request to DebugWriter with the API key redacted
+func (c *Client) dumpRequest(req *http.Request, jsonBody []byte) {
+	clone := req.Clone(req.Context())
+	clone.Header.Set("Authorization", "x-api-key "+redacted)
+	if jsonBody != nil {
+		clone.Body = io.NopCloser(bytes.NewReader(jsonBody))
+	}
+
+	dump, err := httputil.DumpRequestOut(clone, jsonBody != nil)
+	if err != nil {
+		fmt.Fprintf(c.DebugWriter, "--- request dump failed: %v\n", err)
+		return
+	}
+	fmt.Fprintf(c.DebugWriter, "--- request\n%s\n", c.redactSecrets(dump))
+}
+
+// dumpResponse writes the response to DebugWriter with secrets redacted.
+// The response body remains readable by the caller.
+func (c *Client) dumpResponse(resp *http.Response) {
+	dump, err := httputil.DumpResponse(resp, true)
+	if err != nil {
+		fmt.Fprintf(c.DebugWriter, "--- response dump failed: %v\n", err)
+		return
+	}
+	fmt.Fprintf(c.DebugWriter, "--- response\n%s\n", c.redactSecrets(dump))
+}
+
+// redactSecrets removes the API key and password fields from a dump
+func (c *Client) redactSecrets(dump []byte) string {
+	text := secretFieldPattern.ReplaceAllString(string(dump), "${1}"+redacted+"${2}")
+	if apiKey := c.apiKey(); apiKey != "" {
+		text = strings.ReplaceAll(text, apiKey, redacted)
+	}
+	return text
+}
@@ -0,0 +1,39 @@
+package nodemaven
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestDebugWriterRedactsSecrets(t *testing.T) {
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		// A server echoing the key must not leak it either
+		w.Header().Set("X-Echo-Key", "test-key")
+		w.Write([]byte(testUserInfo))
+	})
+	var debug bytes.Buffer
+	client.DebugWriter = &debug
+
+	if _, err := client.GetUserInfo(context.Background()); err != nil {
+		t.Fatalf("GetUserInfo: %v", err)
+	}
+	body := map[string]string{"api_key": "body-secret", "password": "hunter2", "name": "visible"}
+	if _, err := client.Do(context.Background(), http.MethodPost, "/api/v2/base/echo", nil, body); err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+
+	output := debug.String()
+	for _, secret := range []string{"test-key", "pw12345678abc", "body-secret", "hunter2"} {
+		if strings.Contains(output, secret) {
+			t.Errorf("debug output contains %q:\n%s", secret, output)
+		}
+	}
+	for _, want := range []string{"Authorization: x-api-key " + redacted, `"proxy_password": "` + redacted + `"`, "visible", "test_user"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("debug output is missing %q:\n%s", want, output)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package nodemaven
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// DryRunExitIP is the exit IP reported by proxy configurations of a dry-run client. It is
+// from a range reserved for documentation, so it never belongs to a real proxy.
+const DryRunExitIP = "203.0.113.1"
+
+// dryRunHeader marks the synthetic responses of dry-run proxy clients
+const dryRunHeader = "X-NodeMaven-Dry-Run"
+
+// Canned API responses served in dry-run mode
+const (
+	dryRunUserInfo = `{"id": "dry-run", "email": "dry-run@example.com",
+		"proxy_username": "dry_run_user", "proxy_password": "dry_run_password",
+		"traffic_used": 0, "traffic_limit": 0, "subscription": "dry-run", "is_active": true}`
+	dryRunEmptyList = `{"count": 0, "next": null, "previous": null, "results": []}`
+)
+
+// dryRunResponse decodes the canned response for endpoint into target
+func dryRunResponse(endpoint string, target interface{}) error {
+	if target == nil {
+		return nil
+	}
+
+	data := "{}"
+	switch endpoint {
+	case EndpointUserInfo:
+		data = dryRunUserInfo
+	case EndpointCountries, EndpointRegions, EndpointCities, EndpointStatistics:
+		data = dryRunEmptyList
+	}
+
+	if raw, ok := target.(*rawResponse); ok {
+		raw.Body = []byte(data)
+		raw.Header = make(http.Header)
+		raw.Header.Set("Content-Type", "application/json")
+		raw.Header.Set(dryRunHeader, "true")
+		return nil
+	}
+
+	if err := json.Unmarshal([]byte(data), target); err != nil {
+		return fmt.Errorf("failed to decode dry-run response: %w", err)
+	}
+	return nil
+}
+
+// dryRun reports whether the configuration belongs to a dry-run client
+func (p *ProxyConfig) dryRun() bool {
+	return p.client != nil && p.client.DryRun
+}
+
+// dryRunProtocols makes transport answer http and https requests with dryRunTransport instead
+// of connecting anywhere, whatever its proxy or dialer settings
+func dryRunProtocols(transport *http.Transport) {
+	// With HTTP/2 left unconfigured, the https registration does not clash with its upgrade
+	transport.ForceAttemptHTTP2 = false
+	transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	transport.RegisterProtocol("http", dryRunTransport{})
+	transport.RegisterProtocol("https", dryRunTransport{})
+}
+
+// dryRunTransport answers every request with a synthetic 200 response carrying DryRunExitIP
+// in the fields IP checking services use, so GetCurrentIP works offline
+type dryRunTransport struct{}
+
+func (dryRunTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+
+	body := fmt.Sprintf(`{"origin": %q, "ip": %q, "query": %q}`, DryRunExitIP, DryRunExitIP, DryRunExitIP)
+	header := make(http.Header)
+	header.Set("Content-Type", "application/json")
+	header.Set(dryRunHeader, "true")
+
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader([]byte(body))),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
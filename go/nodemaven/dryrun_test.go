@@ -0,0 +1,74 @@
+package nodemaven
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDryRunTransports(t *testing.T) {
+	client, err := NewClient(&Config{APIKey: "dry-run-key", DryRun: true})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	proxyConfig, err := client.GetProxyConfig(&ProxyOptions{Country: "us"})
+	if err != nil {
+		t.Fatalf("GetProxyConfig: %v", err)
+	}
+
+	clients := map[string]*http.Client{
+		"HTTPClient":            proxyConfig.HTTPClient(),
+		"Transport":             {Transport: proxyConfig.Transport()},
+		"HTTPClientInsecure":    proxyConfig.HTTPClientInsecure(),
+		"HTTPClientWithContext": proxyConfig.HTTPClientWithContext(context.Background()),
+		"HTTPClientWithBypass":  proxyConfig.HTTPClientWithBypass([]string{"*.example.com"}),
+		"ProxyRoundTripper":     {Transport: ProxyRoundTripper(client, nil)},
+		"ContextRoundTripper":   {Transport: ContextRoundTripper(client, nil)},
+	}
+	for name, httpClient := range clients {
+		for _, target := range []string{"http://api.example.com/ip", "https://example.org/ip"} {
+			resp, err := httpClient.Get(target)
+			if err != nil {
+				t.Errorf("%s: GET %s: %v", name, target, err)
+				continue
+			}
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if resp.Header.Get(dryRunHeader) != "true" || !strings.Contains(string(body), DryRunExitIP) {
+				t.Errorf("%s: GET %s was not answered by the dry-run stub: %s", name, target, body)
+			}
+		}
+	}
+
+	if err := proxyConfig.Warmup(context.Background(), 2); err != nil {
+		t.Errorf("Warmup: %v", err)
+	}
+	if _, err := proxyConfig.SOCKS5Dialer().Dial("tcp", "example.com:443"); !errors.Is(err, ErrDryRun) {
+		t.Errorf("SOCKS5Dialer.Dial error = %v, want ErrDryRun", err)
+	}
+}
+
+func TestDryRunCapabilities(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("dry-run client sent %s %s", r.Method, r.URL.Path)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{APIKey: "dry-run-key", BaseURL: server.URL, DryRun: true})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	caps, err := client.Capabilities(context.Background())
+	if err != nil {
+		t.Fatalf("Capabilities: %v", err)
+	}
+	for _, endpoint := range capabilityEndpoints {
+		if !caps.Supports(endpoint) {
+			t.Errorf("dry-run Capabilities reports %s as unsupported", endpoint)
+		}
+	}
+}
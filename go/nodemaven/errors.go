@@ -1,8 +1,11 @@
 package nodemaven
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
+	"time"
 )
 
 // NodeMavenError represents a base error from the NodeMaven API
@@ -10,12 +13,35 @@ type NodeMavenError struct {
 	StatusCode int
 	Message    string
 	ErrorData  map[string]interface{}
+	// RequestID is the X-Request-ID of the failed call
+	RequestID string
+	// RetryAfter is the delay requested by the server's Retry-After header, if any
+	RetryAfter time.Duration
 }
 
 func (e *NodeMavenError) Error() string {
 	return fmt.Sprintf("NodeMaven API error (HTTP %d): %s", e.StatusCode, e.Message)
 }
 
+// apiError returns the base error; it is promoted to every typed API error
+func (e *NodeMavenError) apiError() *NodeMavenError {
+	return e
+}
+
+// apiErrorer is implemented by NodeMavenError and the typed errors embedding it
+type apiErrorer interface {
+	apiError() *NodeMavenError
+}
+
+// retryAfter returns the Retry-After delay carried by an API error, or 0
+func retryAfter(err error) time.Duration {
+	var apiErr apiErrorer
+	if errors.As(err, &apiErr) {
+		return apiErr.apiError().RetryAfter
+	}
+	return 0
+}
+
 // AuthenticationError represents an authentication error (401)
 type AuthenticationError struct {
 	*NodeMavenError
@@ -43,6 +69,49 @@ func (e *NotFoundError) Error() string {
 	return fmt.Sprintf("Not found: %s", e.Message)
 }
 
+// AccountSuspendedError is returned when the API reports that the account is suspended or
+// its plan has expired; the plan must be renewed. It unwraps to the error for the response's
+// status code, so a 403 still matches *ForbiddenError and a 401 *AuthenticationError via errors.As.
+// GetUserInfo also returns it, with StatusCode 200, for accounts reported as inactive.
+type AccountSuspendedError struct {
+	*NodeMavenError
+	statusErr error
+}
+
+func (e *AccountSuspendedError) Error() string {
+	return fmt.Sprintf("Account suspended or expired, renew your plan: %s", e.Message)
+}
+
+// Unwrap returns the error for the response's status code, if the API returned an error status
+func (e *AccountSuspendedError) Unwrap() error {
+	return e.statusErr
+}
+
+// suspensionMarkers are error codes and message fragments that identify a suspended or expired account
+var suspensionMarkers = []string{"suspended", "expired", "inactive", "deactivated"}
+
+// isAccountSuspended reports whether an error response describes a suspended or expired account.
+// The raw body fields are checked as well as message, since ErrorMessageFunc may have replaced it.
+func isAccountSuspended(message string, errorData map[string]interface{}) bool {
+	candidates := []string{message}
+	for _, field := range []string{"message", "error", "detail", "code", "error_code", "status"} {
+		if value, ok := errorData[field].(string); ok {
+			candidates = append(candidates, value)
+		}
+	}
+	for _, candidate := range candidates {
+		candidate = strings.ToLower(candidate)
+		for _, marker := range suspensionMarkers {
+			if strings.Contains(candidate, "account "+marker) || strings.Contains(candidate, "account_"+marker) ||
+				strings.Contains(candidate, "subscription "+marker) || strings.Contains(candidate, "subscription_"+marker) ||
+				strings.Contains(candidate, "plan "+marker) || strings.Contains(candidate, "plan_"+marker) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // ValidationError represents a validation error (400, 422)
 type ValidationError struct {
 	*NodeMavenError
@@ -70,15 +139,234 @@ func (e *ServerError) Error() string {
 	return fmt.Sprintf("Server error: %s", e.Message)
 }
 
+// MaintenanceError is returned when the API is unavailable (503), typically during
+// scheduled maintenance. RetryAfter holds the delay the server asked for, if any; retries
+// wait at least that long. It also matches *ServerError via errors.As.
+type MaintenanceError struct {
+	*NodeMavenError
+}
+
+func (e *MaintenanceError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("Service under maintenance, retry after %s: %s", e.RetryAfter, e.Message)
+	}
+	return fmt.Sprintf("Service under maintenance: %s", e.Message)
+}
+
+// Unwrap returns the equivalent ServerError
+func (e *MaintenanceError) Unwrap() error {
+	return &ServerError{NodeMavenError: e.NodeMavenError}
+}
+
+// ErrNoAccountAvailable is returned by MultiClient when every account is exhausted or refused
+var ErrNoAccountAvailable = errors.New("no account with remaining traffic is available")
+
+// ErrUsernameChecksum is returned by VerifyProxyUsername when the checksum does not match
+var ErrUsernameChecksum = errors.New("proxy username checksum mismatch")
+
+// ErrMissingCoordinates is returned by GeoDistance when an IP has no known location
+var ErrMissingCoordinates = errors.New("IP details have no coordinates")
+
+// ErrCountryFilterIgnored is returned by StatisticsByCountry alongside its results when the
+// API appears to ignore the country filter and every country reports account-wide totals
+var ErrCountryFilterIgnored = errors.New("statistics country filter ignored by the API")
+
+// ErrDryRun is returned when a dry-run client is asked for something it cannot simulate,
+// such as a raw SOCKS5 connection
+var ErrDryRun = errors.New("not available in dry-run mode")
+
+// ErrProxyAuthRequired is returned by proxy transports when the gateway rejects the proxy
+// credentials of a CONNECT request with 407 Proxy Authentication Required
+var ErrProxyAuthRequired = errors.New("proxy gateway rejected the credentials (407 Proxy Authentication Required)")
+
+// ErrCredentialsUnavailable matches any CredentialsError via errors.Is
+var ErrCredentialsUnavailable = errors.New("proxy credentials unavailable")
+
+// CredentialsError indicates that the account's proxy credentials are missing, usually because
+// the plan has no proxy access
+type CredentialsError struct {
+	Reason string
+}
+
+func (e *CredentialsError) Error() string {
+	return fmt.Sprintf("Proxy credentials error: %s", e.Reason)
+}
+
+// Is reports whether target is ErrCredentialsUnavailable
+func (e *CredentialsError) Is(target error) bool {
+	return target == ErrCredentialsUnavailable
+}
+
+// UsernameError indicates that no valid proxy username can be built from the targeting
+// options, e.g. conflicting fields or a username exceeding MaxProxyUsernameLength
+type UsernameError struct {
+	Reason string
+}
+
+func (e *UsernameError) Error() string {
+	return fmt.Sprintf("Invalid proxy username: %s", e.Reason)
+}
+
+// AcceptedError is returned when the API accepts a request for asynchronous processing
+// (202 Accepted with a Location header). Pass Location to PollUntilComplete to wait for the result.
+type AcceptedError struct {
+	Location   string
+	RetryAfter time.Duration
+	Data       map[string]interface{}
+}
+
+func (e *AcceptedError) Error() string {
+	return fmt.Sprintf("Request accepted for asynchronous processing; poll %s for the result", e.Location)
+}
+
+// TrafficLimitExceededError is returned by a TrafficGuard when the account's traffic usage
+// has crossed the configured percentage of the plan limit
+type TrafficLimitExceededError struct {
+	TrafficUsed      int64
+	TrafficLimit     int64
+	ThresholdPercent float64
+}
+
+func (e *TrafficLimitExceededError) Error() string {
+	return fmt.Sprintf("Traffic limit exceeded: %s of %s used (threshold %.0f%%)",
+		FormatBytes(e.TrafficUsed), FormatBytes(e.TrafficLimit), e.ThresholdPercent)
+}
+
+// ProxyFailureKind classifies why a proxy connection test failed
+type ProxyFailureKind string
+
+const (
+	// ProxyFailureAuth means the gateway rejected the proxy credentials (407)
+	ProxyFailureAuth ProxyFailureKind = "auth"
+	// ProxyFailureTimeout means the request did not complete in time
+	ProxyFailureTimeout ProxyFailureKind = "timeout"
+	// ProxyFailureConnection means the proxy gateway could not be reached
+	ProxyFailureConnection ProxyFailureKind = "connection"
+	// ProxyFailureTarget means the proxy worked but the target answered with an error or bad data
+	ProxyFailureTarget ProxyFailureKind = "target"
+	// ProxyFailureUnknown covers every other failure
+	ProxyFailureUnknown ProxyFailureKind = "unknown"
+)
+
+// ProxyTestError is returned by TestProxyConnection when the proxy test fails
+type ProxyTestError struct {
+	Kind        ProxyFailureKind
+	Description string
+	Err         error
+}
+
+func (e *ProxyTestError) Error() string {
+	return fmt.Sprintf("%s failed (%s): %v", e.Description, e.Kind, e.Err)
+}
+
+// Unwrap returns the underlying failure
+func (e *ProxyTestError) Unwrap() error {
+	return e.Err
+}
+
+// ipServiceStatusError reports a non-200 answer from an IP checking service
+type ipServiceStatusError struct {
+	StatusCode int
+}
+
+func (e *ipServiceStatusError) Error() string {
+	return fmt.Sprintf("IP service returned status %d", e.StatusCode)
+}
+
+// PartialResultsError is returned when a paginated fetch fails after some pages succeeded.
+// Entries holds the results collected before the failure.
+type PartialResultsError struct {
+	Entries []StatisticEntry
+	Err     error
+}
+
+func (e *PartialResultsError) Error() string {
+	return fmt.Sprintf("partial results (%d entries collected): %v", len(e.Entries), e.Err)
+}
+
+// Unwrap returns the error that interrupted pagination
+func (e *PartialResultsError) Unwrap() error {
+	return e.Err
+}
+
+// LocationFetchError records a failed region (Region empty) or city fetch while building a location tree
+type LocationFetchError struct {
+	Country string
+	Region  string
+	Err     error
+}
+
+func (e *LocationFetchError) Error() string {
+	if e.Region != "" {
+		return fmt.Sprintf("failed to fetch cities for %s/%s: %v", e.Country, e.Region, e.Err)
+	}
+	return fmt.Sprintf("failed to fetch regions for %s: %v", e.Country, e.Err)
+}
+
+// Unwrap returns the underlying fetch error
+func (e *LocationFetchError) Unwrap() error {
+	return e.Err
+}
+
+// LocationTreeError is returned with a partially built LocationTree when some region or
+// city fetches failed
+type LocationTreeError struct {
+	Errors []*LocationFetchError
+}
+
+func (e *LocationTreeError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		messages = append(messages, err.Error())
+	}
+	return fmt.Sprintf("location tree is incomplete (%d failed fetches): %s", len(e.Errors), strings.Join(messages, "; "))
+}
+
+// Unwrap returns the individual fetch errors
+func (e *LocationTreeError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// TargetingError reports targeting that does not exist in the location tree.
+// Level is "country", "region" or "city"; Parent names the enclosing location searched, if any.
+type TargetingError struct {
+	Level  string
+	Value  string
+	Parent string
+}
+
+func (e *TargetingError) Error() string {
+	if e.Parent != "" {
+		return fmt.Sprintf("Targeting error: unknown %s %q in %s", e.Level, e.Value, e.Parent)
+	}
+	return fmt.Sprintf("Targeting error: unknown %s %q", e.Level, e.Value)
+}
+
 // getExceptionForStatusCode returns the appropriate error type based on HTTP status code
-func getExceptionForStatusCode(statusCode int, message string, errorData map[string]interface{}) error {
+func getExceptionForStatusCode(statusCode int, message string, errorData map[string]interface{}, requestID string, retryAfter time.Duration) error {
 	baseError := &NodeMavenError{
 		StatusCode: statusCode,
 		Message:    message,
 		ErrorData:  errorData,
+		RequestID:  requestID,
+		RetryAfter: retryAfter,
 	}
 
-	switch statusCode {
+	statusErr := statusCodeError(baseError)
+	if statusCode >= 400 && statusCode < 500 && statusCode != http.StatusTooManyRequests &&
+		isAccountSuspended(message, errorData) {
+		return &AccountSuspendedError{NodeMavenError: baseError, statusErr: statusErr}
+	}
+	return statusErr
+}
+
+// statusCodeError wraps baseError in the error type for its status code
+func statusCodeError(baseError *NodeMavenError) error {
+	switch statusCode := baseError.StatusCode; statusCode {
 	case http.StatusUnauthorized:
 		return &AuthenticationError{NodeMavenError: baseError}
 	case http.StatusForbidden:
@@ -89,6 +377,8 @@ func getExceptionForStatusCode(statusCode int, message string, errorData map[str
 		return &ValidationError{NodeMavenError: baseError}
 	case http.StatusTooManyRequests:
 		return &RateLimitError{NodeMavenError: baseError}
+	case http.StatusServiceUnavailable:
+		return &MaintenanceError{NodeMavenError: baseError}
 	default:
 		if statusCode >= 500 {
 			return &ServerError{NodeMavenError: baseError}
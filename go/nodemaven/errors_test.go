@@ -0,0 +1,123 @@
+package nodemaven
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMaintenanceErrorRetryAfter(t *testing.T) {
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"detail": "Scheduled maintenance"}`))
+	})
+
+	_, err := client.GetUserInfo(context.Background())
+	var maintenanceErr *MaintenanceError
+	if !errors.As(err, &maintenanceErr) {
+		t.Fatalf("error = %v (%T), want *MaintenanceError", err, err)
+	}
+	if maintenanceErr.RetryAfter != 120*time.Second {
+		t.Errorf("RetryAfter = %v, want 2m0s", maintenanceErr.RetryAfter)
+	}
+	var serverErr *ServerError
+	if !errors.As(err, &serverErr) {
+		t.Error("MaintenanceError does not match *ServerError")
+	}
+}
+
+func TestMaintenanceRetryWaitsRetryAfter(t *testing.T) {
+	var calls int32
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "30")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(testUserInfo))
+	})
+	clk := &recordingClock{}
+	client.clock = clk
+	client.MaxRetries = 1
+	client.Backoff = &Backoff{Base: time.Millisecond, Max: time.Millisecond, Factor: 1}
+
+	if _, err := client.GetUserInfo(context.Background()); err != nil {
+		t.Fatalf("GetUserInfo: %v", err)
+	}
+	if delays := clk.delays(); len(delays) != 1 || delays[0] < 30*time.Second {
+		t.Errorf("retry delays = %v, want one of at least 30s", delays)
+	}
+}
+
+func TestAccountSuspendedError(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		// statusTarget must match the error as well, through Unwrap
+		statusTarget interface{}
+	}{
+		{"403 detail", http.StatusForbidden, `{"detail": "Account suspended"}`, new(*ForbiddenError)},
+		{"401 message", http.StatusUnauthorized, `{"message": "Your plan expired on 2026-09-30"}`, new(*AuthenticationError)},
+		{"404 error code", http.StatusNotFound, `{"error": "not available", "code": "ACCOUNT_DEACTIVATED"}`, new(*NotFoundError)},
+	}
+	for _, tt := range tests {
+		client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tt.status)
+			w.Write([]byte(tt.body))
+		})
+		// A localized message must not hide the suspension in the raw body
+		client.ErrorMessageFunc = func(statusCode int, data map[string]interface{}) string {
+			return "Konto nicht verfügbar"
+		}
+
+		_, err := client.GetUserInfo(context.Background())
+		var suspended *AccountSuspendedError
+		if !errors.As(err, &suspended) {
+			t.Errorf("%s: error = %v (%T), want *AccountSuspendedError", tt.name, err, err)
+			continue
+		}
+		if suspended.StatusCode != tt.status {
+			t.Errorf("%s: StatusCode = %d, want %d", tt.name, suspended.StatusCode, tt.status)
+		}
+		if !errors.As(err, tt.statusTarget) {
+			t.Errorf("%s: error does not match %T", tt.name, tt.statusTarget)
+		}
+	}
+
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"detail": "You do not have permission to perform this action."}`))
+	})
+	_, err := client.GetUserInfo(context.Background())
+	var suspended *AccountSuspendedError
+	if errors.As(err, &suspended) {
+		t.Errorf("plain 403 reported as *AccountSuspendedError: %v", err)
+	}
+}
+
+func TestGetUserInfoInactiveAccount(t *testing.T) {
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id": "u1", "proxy_username": "test_user", "proxy_password": "pw12345678abc", "is_active": false}`))
+	})
+	userInfo, err := client.GetUserInfo(context.Background())
+	var suspended *AccountSuspendedError
+	if !errors.As(err, &suspended) {
+		t.Fatalf("error = %v, want *AccountSuspendedError", err)
+	}
+	if userInfo == nil || userInfo.ID != "u1" {
+		t.Errorf("user info = %+v, want it returned alongside the error", userInfo)
+	}
+
+	// Responses that do not report is_active are not treated as inactive
+	client, _ = newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id": "u1", "proxy_username": "test_user", "proxy_password": "pw12345678abc"}`))
+	})
+	if _, err := client.GetUserInfo(context.Background()); err != nil {
+		t.Errorf("GetUserInfo without is_active: %v", err)
+	}
+}
@@ -0,0 +1,136 @@
+package nodemaven
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// ProxyResponse is the outcome of one request run by ExecuteThroughProxies
+type ProxyResponse struct {
+	// Request is the request as passed in
+	Request *http.Request
+	// Session is the sticky session ID the request was sent through
+	Session    string
+	StatusCode int
+	Header     http.Header
+	// Body is the fully read response body; the underlying body is already closed
+	Body []byte
+	// Err is set when the request could not be completed
+	Err error
+}
+
+// ExecuteThroughProxies sends every request through the proxy with a fresh sticky session
+// each, running at most concurrency requests at once (SampleConcurrency if concurrency <= 0).
+// The responses are returned in the order of requests, with bodies read and closed and
+// failures recorded per request. Requests not started before ctx is done fail with ctx.Err().
+func (c *Client) ExecuteThroughProxies(ctx context.Context, requests []*http.Request, options *ProxyOptions, concurrency int) []ProxyResponse {
+	responses := make([]ProxyResponse, len(requests))
+	for i, req := range requests {
+		responses[i].Request = req
+	}
+	if len(requests) == 0 {
+		return responses
+	}
+
+	userInfo, err := c.proxyCredentials(ctx)
+	if err != nil {
+		for i := range responses {
+			responses[i].Err = err
+		}
+		return responses
+	}
+	options = MergeProxyOptions(c.DefaultProxyOptions, options)
+
+	if concurrency <= 0 {
+		concurrency = SampleConcurrency
+	}
+
+	transport, err := c.sessionTransport(userInfo, options)
+	if err != nil {
+		for i := range responses {
+			responses[i].Err = err
+		}
+		return responses
+	}
+	defer transport.CloseIdleConnections()
+	httpClient := &http.Client{Transport: transport, Timeout: c.Timeout}
+
+	var (
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, concurrency)
+	)
+
+	for i := range requests {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			for j := i; j < len(responses); j++ {
+				responses[j].Err = ctx.Err()
+			}
+			wg.Wait()
+			return responses
+		}
+
+		wg.Add(1)
+		go func(response *ProxyResponse) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			executeThroughProxy(ctx, httpClient, response)
+		}(&responses[i])
+	}
+	wg.Wait()
+
+	return responses
+}
+
+// executeThroughProxy runs response.Request through a new session of httpClient, a client
+// over sessionTransport, and fills in response. Targeting set on the request's context with
+// ContextWithProxyOptions is kept; only the session is replaced.
+func executeThroughProxy(ctx context.Context, httpClient *http.Client, response *ProxyResponse) {
+	if response.Request == nil {
+		response.Err = fmt.Errorf("request is nil")
+		return
+	}
+
+	// The request keeps its own deadline, cancellation and proxy options, and is also
+	// cancelled when the batch context is done
+	reqCtx, cancel := withCancelOn(response.Request.Context(), ctx)
+	defer cancel()
+
+	response.Session = GenerateSessionID()
+	session := &ProxyOptions{Session: response.Session}
+	reqCtx = ContextWithProxyOptions(reqCtx, MergeProxyOptions(ProxyOptionsFromContext(reqCtx), session))
+
+	resp, err := httpClient.Do(response.Request.WithContext(reqCtx))
+	if err != nil {
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		response.Err = fmt.Errorf("proxy request failed: %w", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	response.StatusCode = resp.StatusCode
+	response.Header = resp.Header
+	response.Body, err = io.ReadAll(resp.Body)
+	if err != nil {
+		response.Err = fmt.Errorf("failed to read response body: %w", err)
+	}
+}
+
+// withCancelOn returns a context derived from parent that is also cancelled once other is done
+func withCancelOn(parent, other context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+	go func() {
+		select {
+		case <-other.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
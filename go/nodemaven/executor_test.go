@@ -0,0 +1,53 @@
+package nodemaven
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExecuteThroughProxiesKeepsRequestContext(t *testing.T) {
+	client := newGatewayTestClient(t, &Config{Timeout: 10 * time.Second}, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			select {
+			case <-time.After(5 * time.Second):
+			case <-r.Context().Done():
+			}
+			return
+		}
+		w.Write([]byte(proxyUsername(r)))
+	})
+
+	targeted, err := http.NewRequestWithContext(
+		ContextWithProxyOptions(context.Background(), &ProxyOptions{Country: "fr"}),
+		http.MethodGet, "http://target.example.com/fast", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deadlineCtx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	slow, err := http.NewRequestWithContext(deadlineCtx, http.MethodGet, "http://target.example.com/slow", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	responses := client.ExecuteThroughProxies(context.Background(), []*http.Request{targeted, slow}, nil, 2)
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("batch took %v; the slow request's own deadline was ignored", elapsed)
+	}
+
+	if err := responses[0].Err; err != nil {
+		t.Fatalf("targeted request: %v", err)
+	}
+	username := string(responses[0].Body)
+	if !strings.Contains(username, "-country-fr-") || !strings.Contains(username, "-sid-"+responses[0].Session) {
+		t.Errorf("username = %q, want the request's country and a session of its own", username)
+	}
+	if !errors.Is(responses[1].Err, context.DeadlineExceeded) {
+		t.Errorf("slow request error = %v, want its deadline to be exceeded", responses[1].Err)
+	}
+}
@@ -0,0 +1,131 @@
+package nodemaven
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CountryHealthReport summarizes probes made through a country's proxies
+type CountryHealthReport struct {
+	Country   string
+	Samples   int
+	Successes int
+	// SuccessRate is the percentage of successful probes
+	SuccessRate float64
+	// MedianLatency is the median duration of the successful probes
+	MedianLatency time.Duration
+}
+
+// CountryHealth probes each country samplesPerCountry times through fresh sticky sessions
+// and reports the success rate and median latency per country, keyed by lowercase country
+// code. Probes run concurrently, at most SampleConcurrency at once, each bounded by ProbeTimeout.
+func (c *Client) CountryHealth(ctx context.Context, countries []string, samplesPerCountry int) (map[string]CountryHealthReport, error) {
+	if samplesPerCountry <= 0 {
+		return nil, fmt.Errorf("samples per country must be positive")
+	}
+
+	userInfo, err := c.proxyCredentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	transport, err := c.sessionTransport(userInfo, MergeProxyOptions(c.DefaultProxyOptions, nil))
+	if err != nil {
+		return nil, err
+	}
+	defer transport.CloseIdleConnections()
+	httpClient := &http.Client{Transport: transport, Timeout: c.Timeout}
+
+	var (
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		sem       = make(chan struct{}, SampleConcurrency)
+		latencies = make(map[string][]time.Duration, len(countries))
+		reports   = make(map[string]CountryHealthReport, len(countries))
+	)
+
+	var codes []string
+	for _, country := range countries {
+		country = strings.ToLower(country)
+		if _, seen := reports[country]; !seen {
+			reports[country] = CountryHealthReport{Country: country}
+			codes = append(codes, country)
+		}
+	}
+
+	for _, country := range codes {
+		for i := 0; i < samplesPerCountry; i++ {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				wg.Wait()
+				return nil, ctx.Err()
+			}
+
+			wg.Add(1)
+			go func(country string) {
+				defer wg.Done()
+				defer func() { <-sem }()
+
+				latency, err := c.probeCountry(ctx, httpClient, country)
+
+				mu.Lock()
+				defer mu.Unlock()
+				report := reports[country]
+				report.Samples++
+				if err == nil {
+					report.Successes++
+					latencies[country] = append(latencies[country], latency)
+				}
+				reports[country] = report
+			}(country)
+		}
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	for country, report := range reports {
+		report.SuccessRate = CalculateSuccessRate(report.Successes, report.Samples)
+		report.MedianLatency = medianDuration(latencies[country])
+		reports[country] = report
+	}
+	return reports, nil
+}
+
+// probeCountry requests the exit IP through a fresh session in country, using httpClient over
+// sessionTransport, and returns the latency
+func (c *Client) probeCountry(ctx context.Context, httpClient *http.Client, country string) (time.Duration, error) {
+	probeCtx, cancel := context.WithTimeout(ctx, ProbeTimeout)
+	defer cancel()
+
+	probeCtx = ContextWithProxyOptions(probeCtx, &ProxyOptions{Country: country, Session: GenerateSessionID()})
+
+	start := c.getClock().Now()
+	if _, err := GetCurrentIPContext(probeCtx, httpClient); err != nil {
+		return 0, err
+	}
+	return c.getClock().Now().Sub(start), nil
+}
+
+// medianDuration returns the median of durations, or 0 if there are none
+func medianDuration(durations []time.Duration) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
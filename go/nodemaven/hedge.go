@@ -0,0 +1,185 @@
+package nodemaven
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// HedgedHTTPClient returns an HTTP client that hedges slow requests: when a request has not
+// completed within hedgeDelay, the same request is sent again through a fresh sticky session,
+// up to maxHedges extra times (one further hedge every hedgeDelay, or immediately after an
+// attempt fails). The first successful response is returned and the other attempts are
+// cancelled. This trades extra traffic for lower tail latency. All attempts share one pooled
+// transport, so hedges reuse gateway connections.
+//
+// Only idempotent methods (GET, HEAD, OPTIONS, PUT, DELETE) are hedged, and requests with a
+// body only when req.GetBody is set, as http.NewRequest does for in-memory bodies; other
+// requests are sent once.
+func (c *Client) HedgedHTTPClient(options *ProxyOptions, hedgeDelay time.Duration, maxHedges int) (*http.Client, error) {
+	if hedgeDelay <= 0 {
+		return nil, fmt.Errorf("hedge delay must be positive")
+	}
+	if maxHedges < 0 {
+		return nil, fmt.Errorf("max hedges must not be negative")
+	}
+
+	userInfo, err := c.proxyCredentials(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	transport, err := c.sessionTransport(userInfo, MergeProxyOptions(c.DefaultProxyOptions, options))
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Client{
+		Transport: &hedgedTransport{
+			transport: transport,
+			delay:     hedgeDelay,
+			maxHedges: maxHedges,
+		},
+		Timeout: c.Timeout,
+	}, nil
+}
+
+// hedgedTransport sends each request through one or more proxy sessions and keeps the fastest
+type hedgedTransport struct {
+	transport *http.Transport
+	delay     time.Duration
+	maxHedges int
+}
+
+// CloseIdleConnections closes the idle gateway connections of the shared transport
+func (t *hedgedTransport) CloseIdleConnections() {
+	t.transport.CloseIdleConnections()
+}
+
+// hedgeAttempt is one in-flight copy of a hedged request
+type hedgeAttempt struct {
+	cancel context.CancelFunc
+}
+
+// close cancels the attempt, dropping its connection if the request is still running
+func (a *hedgeAttempt) close() {
+	a.cancel()
+}
+
+// hedgeResult is the outcome of a hedgeAttempt
+type hedgeResult struct {
+	attempt *hedgeAttempt
+	resp    *http.Response
+	err     error
+}
+
+func (t *hedgedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	hasBody := req.Body != nil && req.Body != http.NoBody
+
+	method := req.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	hedges := t.maxHedges
+	if !isIdempotentMethod(method) || (hasBody && req.GetBody == nil) {
+		hedges = 0
+	}
+
+	results := make(chan hedgeResult, hedges+1)
+	var attempts []*hedgeAttempt
+	launch := func() {
+		attemptCtx, cancel := context.WithCancel(ctx)
+		attempt := &hedgeAttempt{cancel: cancel}
+		attempts = append(attempts, attempt)
+
+		// Hedges keep any targeting in the request context but use a session of their own
+		if len(attempts) > 1 {
+			session := &ProxyOptions{Session: GenerateSessionID()}
+			attemptCtx = ContextWithProxyOptions(attemptCtx, MergeProxyOptions(ProxyOptionsFromContext(ctx), session))
+		}
+
+		attemptReq := req.Clone(attemptCtx)
+		if len(attempts) > 1 && hasBody {
+			body, err := req.GetBody()
+			if err != nil {
+				results <- hedgeResult{attempt: attempt, err: fmt.Errorf("failed to replay request body: %w", err)}
+				return
+			}
+			attemptReq.Body = body
+		}
+
+		go func() {
+			resp, err := t.transport.RoundTrip(attemptReq)
+			results <- hedgeResult{attempt: attempt, resp: resp, err: err}
+		}()
+	}
+
+	// abandon cancels the attempts other than winner and cleans up after them once they return
+	pending := 0
+	abandon := func(winner *hedgeAttempt) {
+		for _, attempt := range attempts {
+			if attempt != winner {
+				attempt.cancel()
+			}
+		}
+		go func(pending int) {
+			for i := 0; i < pending; i++ {
+				result := <-results
+				if result.resp != nil {
+					result.resp.Body.Close()
+				}
+				result.attempt.close()
+			}
+		}(pending)
+	}
+
+	launch()
+	pending++
+
+	timer := time.NewTimer(t.delay)
+	defer timer.Stop()
+
+	var lastErr error
+	for pending > 0 {
+		select {
+		case <-timer.C:
+			if len(attempts) <= hedges {
+				launch()
+				pending++
+				timer.Reset(t.delay)
+			}
+		case result := <-results:
+			pending--
+			if result.err == nil {
+				abandon(result.attempt)
+				result.resp.Body = &hedgedBody{ReadCloser: result.resp.Body, attempt: result.attempt}
+				return result.resp, nil
+			}
+			result.attempt.close()
+			lastErr = result.err
+			if len(attempts) <= hedges {
+				launch()
+				pending++
+			}
+		case <-ctx.Done():
+			abandon(nil)
+			return nil, ctx.Err()
+		}
+	}
+	return nil, lastErr
+}
+
+// hedgedBody releases the winning attempt once its response body is closed
+type hedgedBody struct {
+	io.ReadCloser
+	attempt *hedgeAttempt
+}
+
+func (b *hedgedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.attempt.close()
+	return err
+}
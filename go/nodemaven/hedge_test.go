@@ -0,0 +1,130 @@
+package nodemaven
+
+import (
+	"encoding/base64"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// proxyUsername returns the proxy username of a request received by a test gateway
+func proxyUsername(r *http.Request) string {
+	auth := strings.TrimPrefix(r.Header.Get("Proxy-Authorization"), "Basic ")
+	decoded, _ := base64.StdEncoding.DecodeString(auth)
+	username, _, _ := strings.Cut(string(decoded), ":")
+	return username
+}
+
+func TestHedgedRequestUsesNewSession(t *testing.T) {
+	var (
+		mu        sync.Mutex
+		usernames []string
+	)
+	client := newGatewayTestClient(t, &Config{Timeout: 10 * time.Second}, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		usernames = append(usernames, proxyUsername(r))
+		first := len(usernames) == 1
+		mu.Unlock()
+		if first {
+			// The first attempt stalls until the hedge wins and cancels it
+			<-r.Context().Done()
+			return
+		}
+		w.Write([]byte("hedge"))
+	})
+
+	httpClient, err := client.HedgedHTTPClient(&ProxyOptions{Country: "de", Session: "first"}, 50*time.Millisecond, 1)
+	if err != nil {
+		t.Fatalf("HedgedHTTPClient: %v", err)
+	}
+	defer httpClient.CloseIdleConnections()
+
+	resp, err := httpClient.Get("http://target.example.com/")
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "hedge" {
+		t.Errorf("body = %q, want the hedge's response", body)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(usernames) != 2 {
+		t.Fatalf("gateway saw %d attempts, want 2", len(usernames))
+	}
+	if !strings.Contains(usernames[0], "-sid-first") || strings.Contains(usernames[1], "-sid-first") ||
+		!strings.Contains(usernames[1], "-country-de") {
+		t.Errorf("usernames = %q, want the hedge on another session with the same targeting", usernames)
+	}
+}
+
+func TestHedgedClientDoesNotHedgePost(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		calls int
+	)
+	client := newGatewayTestClient(t, &Config{Timeout: 10 * time.Second}, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte("ok"))
+	})
+
+	httpClient, err := client.HedgedHTTPClient(nil, 20*time.Millisecond, 3)
+	if err != nil {
+		t.Fatalf("HedgedHTTPClient: %v", err)
+	}
+	defer httpClient.CloseIdleConnections()
+
+	resp, err := httpClient.Post("http://target.example.com/orders", "application/json", strings.NewReader(`{"id": 1}`))
+	if err != nil {
+		t.Fatalf("POST: %v", err)
+	}
+	resp.Body.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if calls != 1 {
+		t.Errorf("POST was sent %d times, want 1", calls)
+	}
+}
+
+func TestHedgedClientReusesConnections(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		remotes = make(map[string]bool)
+	)
+	client := newGatewayTestClient(t, &Config{Timeout: 10 * time.Second}, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		remotes[r.RemoteAddr] = true
+		mu.Unlock()
+		w.Write([]byte("ok"))
+	})
+
+	httpClient, err := client.HedgedHTTPClient(&ProxyOptions{Session: "sticky"}, time.Second, 1)
+	if err != nil {
+		t.Fatalf("HedgedHTTPClient: %v", err)
+	}
+	defer httpClient.CloseIdleConnections()
+
+	for i := 0; i < 3; i++ {
+		resp, err := httpClient.Get("http://target.example.com/")
+		if err != nil {
+			t.Fatalf("GET %d: %v", i, err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(remotes) != 1 {
+		t.Errorf("requests used %d gateway connections, want 1 reused connection", len(remotes))
+	}
+}
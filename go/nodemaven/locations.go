@@ -0,0 +1,392 @@
+package nodemaven
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultPageSize is the page size used when fetching every page of a list endpoint
+	defaultPageSize = 100
+	// LocationTreeConcurrency bounds the number of parallel requests made by BuildLocationTree
+	LocationTreeConcurrency = 5
+)
+
+// LocationTree is the full country/region/city hierarchy for a connection type
+type LocationTree struct {
+	ConnectionType string
+	Countries      []*CountryNode
+	FetchedAt      time.Time
+}
+
+// CountryNode is a country with its regions
+type CountryNode struct {
+	Country
+	Regions []*RegionNode
+}
+
+// RegionNode is a region with its cities
+type RegionNode struct {
+	Region
+	Cities []City
+}
+
+// Country returns the country with the given code, or nil
+func (t *LocationTree) Country(code string) *CountryNode {
+	for _, country := range t.Countries {
+		if strings.EqualFold(country.Code, code) {
+			return country
+		}
+	}
+	return nil
+}
+
+// Region returns the region with the given code, or nil
+func (n *CountryNode) Region(code string) *RegionNode {
+	for _, region := range n.Regions {
+		if strings.EqualFold(region.Code, code) {
+			return region
+		}
+	}
+	return nil
+}
+
+// City returns the city with the given code, or nil
+func (n *RegionNode) City(code string) *City {
+	for i := range n.Cities {
+		if strings.EqualFold(n.Cities[i].Code, code) {
+			return &n.Cities[i]
+		}
+	}
+	return nil
+}
+
+// locationTreeCache holds built location trees keyed by connection type
+type locationTreeCache struct {
+	mu    sync.Mutex
+	trees map[string]*LocationTree
+}
+
+// ListAllCountries retrieves every page of countries matching the request
+func (c *Client) ListAllCountries(ctx context.Context, req *CountriesRequest) ([]Country, error) {
+	pageReq := CountriesRequest{ConnectionType: c.defaultConnectionType()}
+	if req != nil {
+		pageReq = *req
+	}
+	if pageReq.Limit <= 0 {
+		pageReq.Limit = defaultPageSize
+	}
+
+	var countries []Country
+	err := c.paginate(ctx, EndpointCountries, pageReq.Offset,
+		func(offset int) (listPage, error) {
+			pageReq.Offset = offset
+			return c.GetCountries(ctx, &pageReq)
+		},
+		func() listPage { return &CountriesResponse{} },
+		func(page listPage) error {
+			countries = append(countries, page.(*CountriesResponse).Results...)
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return countries, nil
+}
+
+// ListAllRegions retrieves every page of regions matching the request
+func (c *Client) ListAllRegions(ctx context.Context, req *RegionsRequest) ([]Region, error) {
+	pageReq := RegionsRequest{ConnectionType: c.defaultConnectionType()}
+	if req != nil {
+		pageReq = *req
+	}
+	if pageReq.Limit <= 0 {
+		pageReq.Limit = defaultPageSize
+	}
+
+	var regions []Region
+	err := c.paginate(ctx, EndpointRegions, pageReq.Offset,
+		func(offset int) (listPage, error) {
+			pageReq.Offset = offset
+			return c.GetRegions(ctx, &pageReq)
+		},
+		func() listPage { return &RegionsResponse{} },
+		func(page listPage) error {
+			regions = append(regions, page.(*RegionsResponse).Results...)
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return regions, nil
+}
+
+// ListAllCities retrieves every page of cities matching the request
+func (c *Client) ListAllCities(ctx context.Context, req *CitiesRequest) ([]City, error) {
+	pageReq := CitiesRequest{ConnectionType: c.defaultConnectionType()}
+	if req != nil {
+		pageReq = *req
+	}
+	if pageReq.Limit <= 0 {
+		pageReq.Limit = defaultPageSize
+	}
+
+	var cities []City
+	err := c.paginate(ctx, EndpointCities, pageReq.Offset,
+		func(offset int) (listPage, error) {
+			pageReq.Offset = offset
+			return c.GetCities(ctx, &pageReq)
+		},
+		func() listPage { return &CitiesResponse{} },
+		func(page listPage) error {
+			cities = append(cities, page.(*CitiesResponse).Results...)
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return cities, nil
+}
+
+// BuildLocationTree fetches all countries, their regions and the regions' cities for a
+// connection type and assembles them into a tree. Fetches run in parallel, bounded by
+// LocationTreeConcurrency. The result is cached on the client until ClearLocationTreeCache.
+//
+// A failed region or city fetch does not discard the rest of the tree: the partially built
+// tree is returned together with a *LocationTreeError listing the countries and regions whose
+// children are missing. Partial trees are not cached.
+func (c *Client) BuildLocationTree(ctx context.Context, connectionType string) (*LocationTree, error) {
+	if connectionType == "" {
+		connectionType = c.defaultConnectionType()
+	}
+
+	if tree := c.cachedLocationTree(connectionType); tree != nil {
+		return tree, nil
+	}
+
+	countries, err := c.ListAllCountries(ctx, &CountriesRequest{ConnectionType: connectionType})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch countries: %w", err)
+	}
+
+	tree := &LocationTree{ConnectionType: connectionType}
+	for _, country := range countries {
+		tree.Countries = append(tree.Countries, &CountryNode{Country: country})
+	}
+
+	var (
+		wg       sync.WaitGroup
+		errMu    sync.Mutex
+		treeErrs []*LocationFetchError
+		sem      = make(chan struct{}, LocationTreeConcurrency)
+	)
+
+	// run executes fn with bounded concurrency, recording a failure for the node
+	run := func(countryCode, regionCode string, fn func() error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			defer func() { <-sem }()
+
+			if err := fn(); err != nil {
+				errMu.Lock()
+				treeErrs = append(treeErrs, &LocationFetchError{Country: countryCode, Region: regionCode, Err: err})
+				errMu.Unlock()
+			}
+		}()
+	}
+
+	for _, country := range tree.Countries {
+		country := country
+		run(country.Code, "", func() error {
+			regions, err := c.ListAllRegions(ctx, &RegionsRequest{
+				CountryCode:    country.Code,
+				ConnectionType: connectionType,
+			})
+			if err != nil {
+				return err
+			}
+
+			for _, region := range regions {
+				country.Regions = append(country.Regions, &RegionNode{Region: region})
+			}
+			return nil
+		})
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, country := range tree.Countries {
+		for _, region := range country.Regions {
+			country, region := country, region
+			run(country.Code, region.Code, func() error {
+				cities, err := c.ListAllCities(ctx, &CitiesRequest{
+					CountryCode:    country.Code,
+					RegionCode:     region.Code,
+					ConnectionType: connectionType,
+				})
+				if err != nil {
+					return err
+				}
+
+				region.Cities = cities
+				return nil
+			})
+		}
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	tree.FetchedAt = c.getClock().Now()
+	if len(treeErrs) > 0 {
+		sort.Slice(treeErrs, func(i, j int) bool {
+			if treeErrs[i].Country != treeErrs[j].Country {
+				return treeErrs[i].Country < treeErrs[j].Country
+			}
+			return treeErrs[i].Region < treeErrs[j].Region
+		})
+		return tree, &LocationTreeError{Errors: treeErrs}
+	}
+	c.storeLocationTree(tree)
+
+	return tree, nil
+}
+
+// ClearLocationTreeCache drops cached location trees so the next BuildLocationTree refetches them
+func (c *Client) ClearLocationTreeCache() {
+	if c.locationTrees == nil {
+		return
+	}
+	c.locationTrees.mu.Lock()
+	c.locationTrees.trees = nil
+	c.locationTrees.mu.Unlock()
+}
+
+func (c *Client) cachedLocationTree(connectionType string) *LocationTree {
+	if c.locationTrees == nil {
+		return nil
+	}
+	c.locationTrees.mu.Lock()
+	defer c.locationTrees.mu.Unlock()
+	return c.locationTrees.trees[connectionType]
+}
+
+func (c *Client) storeLocationTree(tree *LocationTree) {
+	if c.locationTrees == nil {
+		return
+	}
+	c.locationTrees.mu.Lock()
+	defer c.locationTrees.mu.Unlock()
+	if c.locationTrees.trees == nil {
+		c.locationTrees.trees = make(map[string]*LocationTree)
+	}
+	c.locationTrees.trees[tree.ConnectionType] = tree
+}
+
+// ValidateTargeting checks offline that the country, region and city targeting in options
+// exist in the tree and are consistent: every city must belong to a requested region (or
+// to any region of the requested country) and every region to the requested country.
+// Failures are reported as *TargetingError naming the level that failed.
+func ValidateTargeting(tree *LocationTree, options *ProxyOptions) error {
+	if tree == nil {
+		return fmt.Errorf("location tree is required")
+	}
+	if options == nil {
+		return nil
+	}
+
+	countries := tree.Countries
+	scope := ""
+	if options.Country != "" {
+		country := tree.Country(options.Country)
+		if country == nil {
+			return &TargetingError{Level: "country", Value: options.Country}
+		}
+		countries = []*CountryNode{country}
+		scope = "country " + country.Code
+	}
+
+	var regionNames []string
+	if options.Region != "" {
+		regionNames = append(regionNames, options.Region)
+	}
+	regionNames = append(regionNames, options.Regions...)
+
+	var regions []*RegionNode
+	if len(regionNames) == 0 {
+		for _, country := range countries {
+			regions = append(regions, country.Regions...)
+		}
+	}
+	for _, name := range regionNames {
+		found := false
+		for _, country := range countries {
+			for _, region := range country.Regions {
+				if matchesLocation(region.Code, region.Name, name) {
+					regions = append(regions, region)
+					found = true
+				}
+			}
+		}
+		if !found {
+			return &TargetingError{Level: "region", Value: name, Parent: scope}
+		}
+	}
+	if len(regionNames) > 0 {
+		scope = "the requested regions"
+	}
+
+	var cityNames []string
+	if options.City != "" {
+		cityNames = append(cityNames, options.City)
+	}
+	cityNames = append(cityNames, options.Cities...)
+
+	for _, name := range cityNames {
+		found := false
+		for _, region := range regions {
+			for _, city := range region.Cities {
+				if matchesLocation(city.Code, city.Name, name) {
+					found = true
+					break
+				}
+			}
+			if found {
+				break
+			}
+		}
+		if !found {
+			return &TargetingError{Level: "city", Value: name, Parent: scope}
+		}
+	}
+
+	return nil
+}
+
+// matchesLocation compares a targeting value against a location code or name,
+// using the same normalization as the proxy username
+func matchesLocation(code, name, value string) bool {
+	normalized := normalizeTargetingValue(value)
+	return strings.EqualFold(code, value) ||
+		normalizeTargetingValue(code) == normalized ||
+		normalizeTargetingValue(name) == normalized
+}
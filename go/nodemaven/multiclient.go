@@ -0,0 +1,95 @@
+package nodemaven
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// MultiClient pools several accounts and issues proxy configurations from the first one
+// with traffic remaining, failing over to the next account when one hits its traffic limit
+// or is refused with 403 Forbidden. It is safe for concurrent use.
+type MultiClient struct {
+	clients []*Client
+
+	mu     sync.RWMutex
+	active int
+}
+
+// NewMultiClient creates a MultiClient over clients, which are tried in the given order
+func NewMultiClient(clients ...*Client) (*MultiClient, error) {
+	if len(clients) == 0 {
+		return nil, fmt.Errorf("at least one client is required")
+	}
+	for i, client := range clients {
+		if client == nil {
+			return nil, fmt.Errorf("client %d is nil", i)
+		}
+	}
+	return &MultiClient{clients: append([]*Client(nil), clients...)}, nil
+}
+
+// Active returns the client of the account currently in use
+func (m *MultiClient) Active() *Client {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.clients[m.active]
+}
+
+// ActiveIndex returns the position of the account currently in use
+func (m *MultiClient) ActiveIndex() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.active
+}
+
+// Clients returns the pooled clients in failover order
+func (m *MultiClient) Clients() []*Client {
+	return append([]*Client(nil), m.clients...)
+}
+
+// GetProxyConfig returns a proxy configuration from the active account, failing over to the
+// following accounts (wrapping around) when it has exhausted its traffic or is forbidden.
+// Other errors are returned without failing over. ErrNoAccountAvailable is returned when
+// every account is exhausted.
+func (m *MultiClient) GetProxyConfig(ctx context.Context, options *ProxyOptions) (*ProxyConfig, error) {
+	start := m.ActiveIndex()
+
+	var lastErr error
+	for i := 0; i < len(m.clients); i++ {
+		index := (start + i) % len(m.clients)
+		client := m.clients[index]
+
+		userInfo, err := client.proxyCredentials(ctx)
+		if err != nil {
+			var forbidden *ForbiddenError
+			if !errors.As(err, &forbidden) {
+				return nil, err
+			}
+			lastErr = err
+			continue
+		}
+		if userInfo.TrafficLimit > 0 && userInfo.TrafficUsed >= userInfo.TrafficLimit {
+			lastErr = fmt.Errorf("account %d has used %s of its %s traffic limit",
+				index, FormatBytes(userInfo.TrafficUsed), FormatBytes(userInfo.TrafficLimit))
+			continue
+		}
+
+		config, err := client.newProxyConfig(userInfo, MergeProxyOptions(client.DefaultProxyOptions, options))
+		if err != nil {
+			return nil, err
+		}
+
+		// Only move the active account if no concurrent call has moved it already
+		m.mu.Lock()
+		if m.active == start {
+			m.active = index
+		}
+		m.mu.Unlock()
+
+		return config, nil
+	}
+
+	return nil, fmt.Errorf("%w: %v", ErrNoAccountAvailable, lastErr)
+}
@@ -0,0 +1,102 @@
+package nodemaven
+
+import (
+	"context"
+	"net/url"
+)
+
+// listPage is implemented by the paginated list responses
+type listPage interface {
+	pageNext() *string
+	pageCount() int
+	pageLen() int
+}
+
+func (r *CountriesResponse) pageNext() *string { return r.Next }
+func (r *CountriesResponse) pageCount() int    { return r.Count }
+func (r *CountriesResponse) pageLen() int      { return len(r.Results) }
+
+func (r *RegionsResponse) pageNext() *string { return r.Next }
+func (r *RegionsResponse) pageCount() int    { return r.Count }
+func (r *RegionsResponse) pageLen() int      { return len(r.Results) }
+
+func (r *CitiesResponse) pageNext() *string { return r.Next }
+func (r *CitiesResponse) pageCount() int    { return r.Count }
+func (r *CitiesResponse) pageLen() int      { return len(r.Results) }
+
+func (r *StatisticsResponse) pageNext() *string { return r.Next }
+func (r *StatisticsResponse) pageCount() int    { return r.Count }
+func (r *StatisticsResponse) pageLen() int      { return len(r.Results) }
+
+// paginate walks every page of a list endpoint, passing each page to handle.
+//
+// The first page is fetched by offset. After that the server-provided Next URL is followed
+// verbatim, so changes to the server's pagination scheme are picked up transparently.
+// Offset math is used only when a page has no Next link but Count says more results remain,
+// or when the Next link points away from the API host. A Next link to a URL already fetched
+// ends the walk instead of looping forever.
+func (c *Client) paginate(ctx context.Context, endpoint string, offset int,
+	fetchOffset func(offset int) (listPage, error),
+	newPage func() listPage,
+	handle func(page listPage) error,
+) error {
+	fetched := make(map[string]bool)
+	page, err := fetchOffset(offset)
+	for {
+		if err != nil {
+			return err
+		}
+		if err := handle(page); err != nil {
+			return err
+		}
+
+		offset += page.pageLen()
+		if page.pageLen() == 0 {
+			return nil
+		}
+
+		if next := page.pageNext(); next != nil && *next != "" {
+			nextURL, ok := c.followableNextURL(endpoint, *next)
+			if ok {
+				if fetched[nextURL.String()] {
+					return nil
+				}
+				fetched[nextURL.String()] = true
+				page = newPage()
+				err = c.fetchNextPage(ctx, endpoint, nextURL, page)
+				continue
+			}
+		} else if page.pageCount() <= offset {
+			return nil
+		}
+
+		page, err = fetchOffset(offset)
+	}
+}
+
+// followableNextURL resolves a Next link and checks that it points at the API host,
+// so the API key is never sent elsewhere
+func (c *Client) followableNextURL(endpoint, next string) (*url.URL, bool) {
+	base, err := c.buildURL(endpoint)
+	if err != nil {
+		return nil, false
+	}
+	return resolveSameHost(base, next)
+}
+
+// resolveSameHost resolves ref against base and reports whether it stays on base's host
+func resolveSameHost(base *url.URL, ref string) (*url.URL, bool) {
+	resolved, err := base.Parse(ref)
+	if err != nil {
+		return nil, false
+	}
+	if resolved.Scheme != base.Scheme || resolved.Host != base.Host {
+		return nil, false
+	}
+	return resolved, true
+}
+
+// fetchNextPage fetches a page from a Next URL into target
+func (c *Client) fetchNextPage(ctx context.Context, endpoint string, nextURL *url.URL, target listPage) error {
+	return c.makeRequestURL(ctx, "GET", endpoint, nextURL, nil, target)
+}
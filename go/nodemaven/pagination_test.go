@@ -0,0 +1,111 @@
+package nodemaven
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPaginationRetriesRateLimitMidway(t *testing.T) {
+	var secondPageCalls int32
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("page") != "2" {
+			fmt.Fprintf(w, `{"count": 2, "next": "%s?page=2", "results": [{"code": "us"}]}`, EndpointCountries)
+			return
+		}
+		if atomic.AddInt32(&secondPageCalls, 1) == 1 {
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"detail": "Too many requests"}`))
+			return
+		}
+		w.Write([]byte(`{"count": 2, "next": null, "results": [{"code": "de"}]}`))
+	})
+	clk := &recordingClock{}
+	client.clock = clk
+	client.MaxRetries = 2
+
+	countries, err := client.ListAllCountries(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ListAllCountries: %v", err)
+	}
+	if len(countries) != 2 || countries[0].Code != "us" || countries[1].Code != "de" {
+		t.Errorf("countries = %+v, want us and de", countries)
+	}
+	if calls := atomic.LoadInt32(&secondPageCalls); calls != 2 {
+		t.Errorf("second page requested %d times, want 2", calls)
+	}
+	if delays := clk.delays(); len(delays) != 1 || delays[0] < 7*time.Second {
+		t.Errorf("retry delays = %v, want one of at least 7s", delays)
+	}
+}
+
+func TestPaginationFollowsNextLinks(t *testing.T) {
+	var requests []string
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.RequestURI())
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			fmt.Fprintf(w, `{"count": 3, "next": "%s?cursor=b", "results": [{"code": "us"}]}`, EndpointCountries)
+		case "b":
+			fmt.Fprintf(w, `{"count": 3, "next": "%s?cursor=c", "results": [{"code": "de"}]}`, EndpointCountries)
+		default:
+			w.Write([]byte(`{"count": 3, "next": null, "results": [{"code": "fr"}]}`))
+		}
+	})
+
+	countries, err := client.ListAllCountries(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ListAllCountries: %v", err)
+	}
+	if len(countries) != 3 {
+		t.Errorf("got %d countries, want 3", len(countries))
+	}
+	if len(requests) != 3 || requests[1] != EndpointCountries+"?cursor=b" || requests[2] != EndpointCountries+"?cursor=c" {
+		t.Errorf("requests = %q, want the Next links followed verbatim", requests)
+	}
+}
+
+func TestPaginationStopsOnRepeatedNextLink(t *testing.T) {
+	var calls int32
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) > 10 {
+			t.Error("pagination kept requesting the same page")
+			w.Write([]byte(`{"count": 0, "next": null, "results": []}`))
+			return
+		}
+		fmt.Fprintf(w, `{"count": 100, "next": "%s?cursor=same", "results": [{"code": "us"}]}`, EndpointCountries)
+	})
+
+	countries, err := client.ListAllCountries(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ListAllCountries: %v", err)
+	}
+	if len(countries) != 2 {
+		t.Errorf("got %d countries, want the first page and the linked page once", len(countries))
+	}
+}
+
+func TestPaginationIgnoresForeignNextLink(t *testing.T) {
+	var requests []string
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.RequestURI())
+		if r.URL.Query().Get("offset") == "1" {
+			w.Write([]byte(`{"count": 2, "next": null, "results": [{"code": "de"}]}`))
+			return
+		}
+		w.Write([]byte(`{"count": 2, "next": "https://elsewhere.example.com/steal?offset=1", "results": [{"code": "us"}]}`))
+	})
+
+	countries, err := client.ListAllCountries(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ListAllCountries: %v", err)
+	}
+	if len(countries) != 2 || len(requests) != 2 {
+		t.Errorf("countries = %+v, requests = %q; want the offset fallback", countries, requests)
+	}
+}
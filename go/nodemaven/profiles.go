@@ -0,0 +1,58 @@
+package nodemaven
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// RegisterProfile registers (or replaces) a named targeting preset such as "us-high" or "de-mobile"
+func (c *Client) RegisterProfile(name string, options *ProxyOptions) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.profiles == nil {
+		c.profiles = make(map[string]*ProxyOptions)
+	}
+	c.profiles[name] = copyProxyOptions(options)
+}
+
+// Profile returns a copy of the named profile's options
+func (c *Client) Profile(name string) (*ProxyOptions, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	options, ok := c.profiles[name]
+	if !ok {
+		return nil, false
+	}
+	return copyProxyOptions(options), true
+}
+
+// ProfileNames returns the registered profile names in sorted order
+func (c *Client) ProfileNames() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	names := make([]string, 0, len(c.profiles))
+	for name := range c.profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetProxyConfigByProfile builds a proxy configuration from a registered profile,
+// merged over DefaultProxyOptions. Unknown profiles return a *NotFoundError.
+func (c *Client) GetProxyConfigByProfile(ctx context.Context, name string) (*ProxyConfig, error) {
+	options, ok := c.Profile(name)
+	if !ok {
+		return nil, &NotFoundError{NodeMavenError: &NodeMavenError{
+			Message: fmt.Sprintf("proxy profile %q is not registered", name),
+		}}
+	}
+
+	userInfo, err := c.proxyCredentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.newProxyConfig(userInfo, MergeProxyOptions(c.DefaultProxyOptions, options))
+}
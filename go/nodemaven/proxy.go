@@ -0,0 +1,324 @@
+package nodemaven
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+)
+
+// Proxy combines a proxy configuration with the API client that issued it, so a single
+// value can make proxied requests and query account usage.
+// The lower-level Client and ProxyConfig remain available through it.
+//
+// Requests that fail with 407 Proxy Authentication Required are retried once with freshly
+// fetched credentials, covering the propagation lag after plan or credential changes, and
+// credentials are fetched again after the client's API key changes (see SetAPIKey).
+// The embedded ProxyConfig keeps the credentials the Proxy was created with.
+type Proxy struct {
+	*ProxyConfig
+	client     *Client
+	httpClient *http.Client
+}
+
+// NewProxy creates a client from config (falling back to environment variables),
+// fetches proxy credentials and returns a ready-to-use Proxy
+func NewProxy(config *Config, options *ProxyOptions) (*Proxy, error) {
+	client, err := NewClient(config)
+	if err != nil {
+		return nil, err
+	}
+	return client.Proxy(options)
+}
+
+// ProxyClientFromEnv builds a client from the NODEMAVEN_* environment variables, fetches
+// proxy credentials and returns an *http.Client that sends requests through the proxy.
+// It is meant as one-line setup for integration tests.
+func ProxyClientFromEnv(options *ProxyOptions) (*http.Client, error) {
+	if os.Getenv("NODEMAVEN_APIKEY") == "" {
+		return nil, fmt.Errorf("NODEMAVEN_APIKEY environment variable is not set")
+	}
+
+	proxy, err := NewProxy(nil, options)
+	if err != nil {
+		return nil, err
+	}
+	return proxy.httpClient, nil
+}
+
+// Proxy returns a ready-to-use Proxy for the given targeting options
+func (c *Client) Proxy(options *ProxyOptions) (*Proxy, error) {
+	proxyConfig, err := c.GetProxyConfig(options)
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient := proxyConfig.HTTPClient()
+	httpClient.Transport = &proxyAuthRetryTransport{
+		client:  c,
+		options: proxyConfig.options,
+		base:    httpClient.Transport,
+		apiKey:  c.apiKey(),
+	}
+
+	return &Proxy{
+		ProxyConfig: proxyConfig,
+		client:      c,
+		httpClient:  httpClient,
+	}, nil
+}
+
+// Client returns the API client behind the proxy
+func (p *Proxy) Client() *Client {
+	return p.client
+}
+
+// Do sends a request through the proxy
+func (p *Proxy) Do(req *http.Request) (*http.Response, error) {
+	return p.httpClient.Do(req)
+}
+
+// Get issues a GET request through the proxy
+func (p *Proxy) Get(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	return p.Do(req)
+}
+
+// ExitIP returns the current exit IP address of the proxy
+func (p *Proxy) ExitIP() (string, error) {
+	return GetCurrentIP(p.httpClient)
+}
+
+// Usage returns the account information, including traffic used and the plan limit
+func (p *Proxy) Usage(ctx context.Context) (*UserInfo, error) {
+	return p.client.GetUserInfo(ctx)
+}
+
+// ProxyRoundTripper returns an http.RoundTripper that sends requests through the proxy with
+// the given targeting, for assigning to the Transport of any http.Client. Credentials are
+// fetched on first use and refreshed transparently when the gateway answers 407 or the
+// client's API key changes.
+func ProxyRoundTripper(client *Client, options *ProxyOptions) http.RoundTripper {
+	return &proxyAuthRetryTransport{
+		client:  client,
+		options: MergeProxyOptions(client.DefaultProxyOptions, options),
+	}
+}
+
+// proxyAuthRetryTransport sends requests through the proxy, fetching credentials lazily when
+// created without a base transport. A request that fails with 407 Proxy Authentication
+// Required is retried once with refreshed credentials. Credentials are also fetched again
+// once the client's API key changes (see SetAPIKey), so a key rotation switches accounts
+// without waiting for a 407.
+type proxyAuthRetryTransport struct {
+	client  *Client
+	options *ProxyOptions
+	// contextOptions targets each request with the options in its context, see ContextRoundTripper
+	contextOptions bool
+
+	mu   sync.Mutex
+	base http.RoundTripper
+	// apiKey is the API key the credentials of base were fetched with
+	apiKey string
+	// refreshing is the credential refresh in progress, shared by the requests waiting on it
+	refreshing *credentialRefresh
+}
+
+// credentialRefresh is one in-flight fetch of proxy credentials
+type credentialRefresh struct {
+	done chan struct{}
+	base http.RoundTripper
+	err  error
+}
+
+func (t *proxyAuthRetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	base := t.base
+	current := base != nil && t.apiKey == t.client.apiKey()
+	t.mu.Unlock()
+
+	if !current {
+		var err error
+		if base, err = t.refresh(req.Context(), base); err != nil {
+			if req.Body != nil {
+				req.Body.Close()
+			}
+			return nil, err
+		}
+	}
+
+	resp, err := base.RoundTrip(req)
+	if !isProxyAuthFailure(resp, err) {
+		return resp, err
+	}
+
+	// Only retry when the body can be replayed
+	retry := req.Clone(req.Context())
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return resp, err
+		}
+		body, bodyErr := req.GetBody()
+		if bodyErr != nil {
+			return resp, err
+		}
+		retry.Body = body
+	}
+
+	refreshed, refreshErr := t.refresh(req.Context(), base)
+	if refreshErr != nil {
+		return resp, err
+	}
+	if resp != nil {
+		resp.Body.Close()
+	}
+	return refreshed.RoundTrip(retry)
+}
+
+// refresh fetches the proxy credentials and swaps in a transport using them. If another
+// request already refreshed since stale was read, its transport is reused; concurrent
+// refreshes share one fetch, made without holding the lock so other requests keep flowing.
+func (t *proxyAuthRetryTransport) refresh(ctx context.Context, stale http.RoundTripper) (http.RoundTripper, error) {
+	t.mu.Lock()
+	if t.base != stale && t.apiKey == t.client.apiKey() {
+		base := t.base
+		t.mu.Unlock()
+		return base, nil
+	}
+	if call := t.refreshing; call != nil {
+		t.mu.Unlock()
+		select {
+		case <-call.done:
+			return call.base, call.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	call := &credentialRefresh{done: make(chan struct{})}
+	t.refreshing = call
+	options := t.options
+	t.mu.Unlock()
+
+	apiKey := t.client.apiKey()
+	base, options, err := t.fetch(ctx, options)
+
+	t.mu.Lock()
+	old := t.base
+	if err == nil {
+		t.base, t.options, t.apiKey = base, options, apiKey
+	}
+	t.refreshing = nil
+	t.mu.Unlock()
+
+	call.base, call.err = base, err
+	close(call.done)
+
+	// Requests still running on the old transport finish; its idle connections go now
+	if closer, ok := old.(interface{ CloseIdleConnections() }); ok && err == nil {
+		closer.CloseIdleConnections()
+	}
+	return base, err
+}
+
+// fetch gets the current proxy credentials and builds a transport for options with them.
+// The returned options keep any generated AutoSession ID, so refreshes stay on one session.
+func (t *proxyAuthRetryTransport) fetch(ctx context.Context, options *ProxyOptions) (http.RoundTripper, *ProxyOptions, error) {
+	userInfo, err := t.client.proxyCredentials(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	config, err := t.client.newProxyConfig(userInfo, options)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !t.contextOptions {
+		return config.Transport(), config.options, nil
+	}
+	transport, err := t.client.sessionTransport(userInfo, config.options)
+	if err != nil {
+		return nil, nil, err
+	}
+	return transport, config.options, nil
+}
+
+// checkProxyConnectResponse turns a 407 answer to a CONNECT into ErrProxyAuthRequired, so
+// rejected credentials can be told apart from other tunnel failures
+func checkProxyConnectResponse(ctx context.Context, proxyURL *url.URL, connectReq *http.Request, connectRes *http.Response) error {
+	if connectRes.StatusCode == http.StatusProxyAuthRequired {
+		return ErrProxyAuthRequired
+	}
+	return nil
+}
+
+// isProxyAuthFailure reports whether a round trip was rejected by the proxy with 407.
+// Plain HTTP requests get the 407 response; HTTPS requests fail the CONNECT with
+// ErrProxyAuthRequired.
+func isProxyAuthFailure(resp *http.Response, err error) bool {
+	if err != nil {
+		return errors.Is(err, ErrProxyAuthRequired)
+	}
+	return resp != nil && resp.StatusCode == http.StatusProxyAuthRequired
+}
+
+// proxyOptionsKey is the context key carrying per-request proxy options
+type proxyOptionsKey struct{}
+
+// ContextWithProxyOptions returns a context carrying proxy targeting for the requests made
+// with it, as read by ContextRoundTripper
+func ContextWithProxyOptions(ctx context.Context, options *ProxyOptions) context.Context {
+	return context.WithValue(ctx, proxyOptionsKey{}, copyProxyOptions(options))
+}
+
+// ProxyOptionsFromContext returns the proxy options stored by ContextWithProxyOptions, or nil
+func ProxyOptionsFromContext(ctx context.Context) *ProxyOptions {
+	options, _ := ctx.Value(proxyOptionsKey{}).(*ProxyOptions)
+	return options
+}
+
+// sessionTransport returns one pooled transport through the gateway whose proxy username is
+// built per request, from options overlaid with any options in the request context (see
+// ContextWithProxyOptions). Requests on different sessions share the transport instead of
+// each opening its own; callers close its idle connections once they are done with it.
+func (c *Client) sessionTransport(userInfo *UserInfo, options *ProxyOptions) (*http.Transport, error) {
+	config, err := c.newProxyConfig(userInfo, options)
+	if err != nil {
+		return nil, err
+	}
+
+	// config.options keeps a generated AutoSession ID stable across requests
+	base := config.options
+	transport := config.Transport()
+	transport.Proxy = func(req *http.Request) (*url.URL, error) {
+		requestConfig, err := c.newProxyConfig(userInfo, MergeProxyOptions(base, ProxyOptionsFromContext(req.Context())))
+		if err != nil {
+			return nil, err
+		}
+		return url.Parse(requestConfig.ProxyURL())
+	}
+	return transport, nil
+}
+
+// ContextRoundTripper returns an http.RoundTripper that targets each request with the proxy
+// options in its context (see ContextWithProxyOptions), layered over defaults and the client's
+// DefaultProxyOptions; requests without options in their context use the defaults. A single
+// http.Client can thus serve differently targeted requests, e.g. per incoming request in a
+// web service. Connections are pooled per resulting proxy username. An AutoSession in the
+// defaults picks one session for the round tripper; set Session in the context options to
+// give requests sessions of their own.
+//
+// Credentials are fetched on first use and refreshed transparently when the gateway answers
+// 407 or the client's API key changes.
+func ContextRoundTripper(client *Client, defaults *ProxyOptions) http.RoundTripper {
+	return &proxyAuthRetryTransport{
+		client:         client,
+		options:        MergeProxyOptions(client.DefaultProxyOptions, defaults),
+		contextOptions: true,
+	}
+}
@@ -0,0 +1,237 @@
+package nodemaven
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// accountsAPI serves user info whose proxy username is derived from the request's API key,
+// so tests can tell which account's credentials a gateway request used
+func accountsAPI(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimPrefix(r.Header.Get("Authorization"), "x-api-key ")
+	fmt.Fprintf(w, `{"proxy_username": "user_%s", "proxy_password": "pw12345678abc"}`, strings.ReplaceAll(key, "-", "_"))
+}
+
+// recordingGateway answers proxied requests with 200 and records their proxy usernames
+type recordingGateway struct {
+	mu        sync.Mutex
+	usernames []string
+}
+
+func (g *recordingGateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g.mu.Lock()
+	g.usernames = append(g.usernames, proxyUsername(r))
+	g.mu.Unlock()
+	w.Write([]byte("ok"))
+}
+
+func (g *recordingGateway) last() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.usernames[len(g.usernames)-1]
+}
+
+// get requests target with httpClient and discards the response
+func get(t *testing.T, ctx context.Context, httpClient *http.Client, target string) {
+	t.Helper()
+	req, err := http.NewRequestWithContext(ctx, "GET", target, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET %s: %v", target, err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+}
+
+func TestContextRoundTripperTargetsPerRequest(t *testing.T) {
+	gateway := &recordingGateway{}
+	client := newGatewayTestClientWithAPI(t, &Config{Timeout: 10 * time.Second}, accountsAPI, gateway.ServeHTTP)
+	httpClient := &http.Client{Transport: ContextRoundTripper(client, &ProxyOptions{Country: "us"})}
+
+	get(t, context.Background(), httpClient, "http://target.example.com/")
+	if username := gateway.last(); !strings.HasPrefix(username, "user_test_key-country-us") {
+		t.Errorf("default request username = %q, want country us", username)
+	}
+
+	ctx := ContextWithProxyOptions(context.Background(), &ProxyOptions{Country: "de", Session: "abc"})
+	get(t, ctx, httpClient, "http://target.example.com/")
+	if username := gateway.last(); !strings.Contains(username, "-country-de") || !strings.Contains(username, "-sid-abc") {
+		t.Errorf("context request username = %q, want country de with session abc", username)
+	}
+}
+
+func TestProxyRoundTrippersFollowAPIKeyChanges(t *testing.T) {
+	gateway := &recordingGateway{}
+	client := newGatewayTestClientWithAPI(t, &Config{Timeout: 10 * time.Second}, accountsAPI, gateway.ServeHTTP)
+
+	proxy, err := client.Proxy(nil)
+	if err != nil {
+		t.Fatalf("Proxy: %v", err)
+	}
+	roundTrippers := map[string]*http.Client{
+		"Proxy":               proxy.httpClient,
+		"ProxyRoundTripper":   {Transport: ProxyRoundTripper(client, nil)},
+		"ContextRoundTripper": {Transport: ContextRoundTripper(client, nil)},
+	}
+	for name, httpClient := range roundTrippers {
+		get(t, context.Background(), httpClient, "http://target.example.com/")
+		if username := gateway.last(); !strings.HasPrefix(username, "user_test_key") {
+			t.Errorf("%s: username before rotation = %q", name, username)
+		}
+	}
+
+	if err := client.SetAPIKey(context.Background(), "rotated-key"); err != nil {
+		t.Fatalf("SetAPIKey: %v", err)
+	}
+	for name, httpClient := range roundTrippers {
+		get(t, context.Background(), httpClient, "http://target.example.com/")
+		if username := gateway.last(); !strings.HasPrefix(username, "user_rotated_key") {
+			t.Errorf("%s: username after rotation = %q, want the new account's", name, username)
+		}
+	}
+}
+
+// rotatingCredentialsGateway serves an API whose proxy password changes after the first
+// fetch, and a gateway that only accepts the newest password
+type rotatingCredentialsGateway struct {
+	mu       sync.Mutex
+	fetches  int
+	accepted int
+}
+
+func (g *rotatingCredentialsGateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodConnect && !strings.HasPrefix(r.RequestURI, "http://") {
+		time.Sleep(20 * time.Millisecond)
+		g.mu.Lock()
+		g.fetches++
+		password := fmt.Sprintf("password_%04d", g.fetches)
+		g.mu.Unlock()
+		fmt.Fprintf(w, `{"proxy_username": "test_user", "proxy_password": %q}`, password)
+		return
+	}
+
+	auth := strings.TrimPrefix(r.Header.Get("Proxy-Authorization"), "Basic ")
+	decoded, _ := base64.StdEncoding.DecodeString(auth)
+	g.mu.Lock()
+	current := strings.HasSuffix(string(decoded), fmt.Sprintf(":password_%04d", g.fetches)) && g.fetches > 1
+	if current {
+		g.accepted++
+	}
+	g.mu.Unlock()
+	if !current {
+		w.WriteHeader(http.StatusProxyAuthRequired)
+		return
+	}
+	w.Write([]byte("ok"))
+}
+
+func newRotatingGatewayClient(t *testing.T, gateway *rotatingCredentialsGateway) *Client {
+	t.Helper()
+	server := httptest.NewServer(gateway)
+	t.Cleanup(server.Close)
+	host, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	httpPort, _ := strconv.Atoi(port)
+
+	client, err := NewClient(&Config{APIKey: "test-key", BaseURL: server.URL, ProxyHost: host, HTTPPort: httpPort})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	return client
+}
+
+func TestProxyRoundTripperRefreshesOnceOn407(t *testing.T) {
+	gateway := &rotatingCredentialsGateway{}
+	client := newRotatingGatewayClient(t, gateway)
+	httpClient := &http.Client{Transport: ProxyRoundTripper(client, nil), Timeout: 10 * time.Second}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := httpClient.Get("http://target.example.com/")
+			if err != nil {
+				t.Errorf("GET: %v", err)
+				return
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("status = %d, want 200 after the credential refresh", resp.StatusCode)
+			}
+		}()
+	}
+	wg.Wait()
+
+	gateway.mu.Lock()
+	defer gateway.mu.Unlock()
+	if gateway.fetches != 2 {
+		t.Errorf("credentials fetched %d times, want 2 (first use and one shared refresh)", gateway.fetches)
+	}
+	if gateway.accepted != 8 {
+		t.Errorf("gateway accepted %d requests, want 8", gateway.accepted)
+	}
+}
+
+func TestConnect407IsTyped(t *testing.T) {
+	gateway := &rotatingCredentialsGateway{}
+	client := newRotatingGatewayClient(t, gateway)
+	proxyConfig, err := client.GetProxyConfig(nil)
+	if err != nil {
+		t.Fatalf("GetProxyConfig: %v", err)
+	}
+
+	_, err = (&http.Client{Transport: proxyConfig.Transport()}).Get("https://target.example.com/")
+	if !errors.Is(err, ErrProxyAuthRequired) {
+		t.Errorf("error = %v, want ErrProxyAuthRequired", err)
+	}
+}
+
+func TestHTTPClientWithBypassKeepsTransportSettings(t *testing.T) {
+	gateway := &rotatingCredentialsGateway{}
+	client := newRotatingGatewayClient(t, gateway)
+	client.DialTimeout = 5 * time.Second
+	proxyConfig, err := client.GetProxyConfig(nil)
+	if err != nil {
+		t.Fatalf("GetProxyConfig: %v", err)
+	}
+	proxyConfig.DisableHTTP2 = true
+
+	direct := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("direct"))
+	}))
+	defer direct.Close()
+
+	httpClient := proxyConfig.HTTPClientWithBypass([]string{"127.0.0.1"})
+	transport := httpClient.Transport.(*http.Transport)
+	if transport.DialContext == nil || transport.ForceAttemptHTTP2 || transport.IdleConnTimeout != proxyIdleConnTimeout {
+		t.Errorf("bypass transport lost the Transport settings: %+v", transport)
+	}
+
+	resp, err := httpClient.Get(direct.URL)
+	if err != nil {
+		t.Fatalf("GET bypassed host: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "direct" {
+		t.Errorf("bypassed host answered %q, want the direct response", body)
+	}
+
+	if _, err := httpClient.Get("https://target.example.com/"); !errors.Is(err, ErrProxyAuthRequired) {
+		t.Errorf("proxied CONNECT error = %v, want ErrProxyAuthRequired", err)
+	}
+}
@@ -0,0 +1,109 @@
+package nodemaven
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+	"time"
+)
+
+// Session represents a sticky proxy session. Requests made with the same session ID
+// keep the same exit IP for as long as the gateway holds the session.
+// A Session is not safe for concurrent mutation.
+type Session struct {
+	ID      string
+	Created time.Time
+	Options *ProxyOptions
+	// Jar holds the cookies of this session; it is replaced on Refresh so cookies
+	// never outlive the exit IP they were set for. Callers may inspect or seed it.
+	Jar http.CookieJar
+
+	clock clock
+}
+
+// newCookieJar returns an empty in-memory cookie jar
+func newCookieJar() http.CookieJar {
+	// cookiejar.New only fails for invalid options
+	jar, _ := cookiejar.New(nil)
+	return jar
+}
+
+// NewSession creates a session with a freshly generated ID and the given targeting options
+func NewSession(options *ProxyOptions) *Session {
+	return newSession(GenerateSessionID(), options, systemClock)
+}
+
+// NewSessionWithID creates a session from an existing ID, sanitizing it for use in the proxy username
+func NewSessionWithID(id string, options *ProxyOptions) *Session {
+	return newSession(SanitizeSessionID(id), options, systemClock)
+}
+
+// NewSession creates a session like the package-level NewSession whose age is measured
+// with the client's time source
+func (c *Client) NewSession(options *ProxyOptions) *Session {
+	return newSession(GenerateSessionID(), options, c.getClock())
+}
+
+// NewSessionWithID creates a session like the package-level NewSessionWithID whose age is
+// measured with the client's time source
+func (c *Client) NewSessionWithID(id string, options *ProxyOptions) *Session {
+	return newSession(SanitizeSessionID(id), options, c.getClock())
+}
+
+// newSession creates a session timed by clk
+func newSession(id string, options *ProxyOptions, clk clock) *Session {
+	return &Session{
+		ID:      id,
+		Created: clk.Now(),
+		Options: options,
+		Jar:     newCookieJar(),
+		clock:   clk,
+	}
+}
+
+// Refresh replaces the session ID with a new one, which results in a new exit IP
+func (s *Session) Refresh() {
+	s.ID = GenerateSessionID()
+	s.Created = s.getClock().Now()
+	s.Jar = newCookieJar()
+}
+
+// Age returns how long ago the session was created or last refreshed
+func (s *Session) Age() time.Duration {
+	return s.getClock().Now().Sub(s.Created)
+}
+
+// getClock returns the session's time source
+func (s *Session) getClock() clock {
+	if s.clock == nil {
+		return systemClock
+	}
+	return s.clock
+}
+
+// ProxyOptions returns a copy of the session's targeting options with the session ID applied
+func (s *Session) ProxyOptions() *ProxyOptions {
+	options := &ProxyOptions{}
+	if s.Options != nil {
+		*options = *s.Options
+	}
+	options.Session = s.ID
+	return options
+}
+
+// ProxyConfig materializes a proxy configuration for this session
+func (s *Session) ProxyConfig(client *Client) (*ProxyConfig, error) {
+	return client.GetProxyConfig(s.ProxyOptions())
+}
+
+// HTTPClient returns an HTTP client that sends requests through this session's exit IP
+// and keeps cookies in the session's Jar
+func (s *Session) HTTPClient(client *Client) (*http.Client, error) {
+	proxyConfig, err := s.ProxyConfig(client)
+	if err != nil {
+		return nil, err
+	}
+	if s.Jar == nil {
+		s.Jar = newCookieJar()
+	}
+	return proxyConfig.HTTPClientWithJar(s.Jar), nil
+}
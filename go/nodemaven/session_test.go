@@ -0,0 +1,36 @@
+package nodemaven
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSessionUsesClientClock(t *testing.T) {
+	clk := newFakeClock(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+	client := &Client{clock: clk}
+
+	session := client.NewSession(&ProxyOptions{Country: "de"})
+	if !session.Created.Equal(clk.Now()) {
+		t.Errorf("Created = %v, want the client clock's %v", session.Created, clk.Now())
+	}
+
+	clk.Advance(5 * time.Minute)
+	if age := session.Age(); age != 5*time.Minute {
+		t.Errorf("Age = %v, want 5m0s", age)
+	}
+
+	oldID := session.ID
+	session.Refresh()
+	if session.ID == oldID || session.Age() != 0 {
+		t.Errorf("after Refresh: ID %q (was %q), Age %v; want a new ID and zero age", session.ID, oldID, session.Age())
+	}
+
+	restored := client.NewSessionWithID("saved-id_1", nil)
+	if restored.ID != "savedid_1" {
+		t.Errorf("NewSessionWithID ID = %q, want the sanitized savedid_1", restored.ID)
+	}
+	clk.Advance(time.Hour)
+	if restored.Age() != time.Hour {
+		t.Errorf("restored Age = %v, want 1h0m0s", restored.Age())
+	}
+}
@@ -0,0 +1,85 @@
+package nodemaven
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// SessionStore persists sticky session IDs by key (e.g. a user or campaign ID), so a key keeps
+// its exit IP across process restarts. Implementations backed by Redis, files or databases
+// can be plugged into LoadSession; MemorySessionStore is the in-process default.
+type SessionStore interface {
+	// Get returns the session ID stored for key; ok is false when there is none
+	Get(ctx context.Context, key string) (id string, ok bool, err error)
+	// Set stores the session ID for key, replacing any previous one
+	Set(ctx context.Context, key, id string) error
+	// Delete removes the session ID of key; deleting a missing key is not an error
+	Delete(ctx context.Context, key string) error
+}
+
+// MemorySessionStore is a SessionStore kept in memory. It is safe for concurrent use but does
+// not survive restarts; use it for tests or as a cache in front of a persistent store.
+type MemorySessionStore struct {
+	mu       sync.RWMutex
+	sessions map[string]string
+}
+
+// NewMemorySessionStore returns an empty in-memory session store
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{sessions: make(map[string]string)}
+}
+
+// Get returns the session ID stored for key
+func (s *MemorySessionStore) Get(ctx context.Context, key string) (string, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	id, ok := s.sessions[key]
+	return id, ok, nil
+}
+
+// Set stores the session ID for key
+func (s *MemorySessionStore) Set(ctx context.Context, key, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sessions == nil {
+		s.sessions = make(map[string]string)
+	}
+	s.sessions[key] = id
+	return nil
+}
+
+// Delete removes the session ID of key
+func (s *MemorySessionStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, key)
+	return nil
+}
+
+// LoadSession returns the session stored for key with the given targeting, so the key keeps
+// its exit IP across restarts. When the store has no session for key, a new one is created
+// and stored. After Refresh, call SaveSession to persist the new ID.
+func LoadSession(ctx context.Context, store SessionStore, key string, options *ProxyOptions) (*Session, error) {
+	id, ok, err := store.Get(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load session %q: %w", key, err)
+	}
+	if ok && id != "" {
+		return NewSessionWithID(id, options), nil
+	}
+
+	session := NewSession(options)
+	if err := SaveSession(ctx, store, key, session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// SaveSession stores the ID of session under key
+func SaveSession(ctx context.Context, store SessionStore, key string, session *Session) error {
+	if err := store.Set(ctx, key, session.ID); err != nil {
+		return fmt.Errorf("failed to save session %q: %w", key, err)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package nodemaven
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ClockSkew returns the difference between local and server time (positive when the local
+// clock is ahead) as measured from the Date header of the most recent API response
+func (c *Client) ClockSkew() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.clockSkew
+}
+
+// checkClockSkew records the skew between local time and the response's Date header and
+// reports it when it exceeds MaxClockSkew. Responses without a valid Date are ignored.
+func (c *Client) checkClockSkew(resp *http.Response) {
+	date, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return
+	}
+	skew := c.getClock().Now().Sub(date)
+
+	c.mu.Lock()
+	c.clockSkew = skew
+	c.mu.Unlock()
+
+	if skew <= MaxClockSkew && skew >= -MaxClockSkew {
+		return
+	}
+	if c.DebugWriter != nil {
+		fmt.Fprintf(c.DebugWriter, "--- local clock is %s off the server's Date header\n", skew.Round(time.Second))
+	}
+	if c.ClockSkewFunc != nil {
+		c.ClockSkewFunc(skew)
+	}
+}
@@ -0,0 +1,292 @@
+package nodemaven
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+// SOCKS5 protocol constants (RFC 1928 / RFC 1929)
+const (
+	socks5Version          = 0x05
+	socks5AuthNone         = 0x00
+	socks5AuthPassword     = 0x02
+	socks5AuthNoAcceptable = 0xff
+	socks5AuthVersion      = 0x01
+	socks5CmdConnect       = 0x01
+	socks5AddrIPv4         = 0x01
+	socks5AddrDomain       = 0x03
+	socks5AddrIPv6         = 0x04
+)
+
+// DefaultDNSCheckHost is the hostname resolved through the proxy by VerifyRemoteDNS
+const DefaultDNSCheckHost = "httpbin.org"
+
+// SOCKS5Dialer dials TCP connections through a SOCKS5 proxy.
+//
+// By default hostnames are passed to the proxy unresolved, so DNS resolution happens at
+// the proxy (socks5h semantics) and lookups for target hosts never leave the local machine.
+// With LocalDNS set, hostnames are resolved locally and only the IP address is sent to
+// the proxy (socks5 semantics), which exposes the lookups to the local resolver.
+type SOCKS5Dialer struct {
+	ProxyAddr string
+	Username  string
+	Password  string
+	LocalDNS  bool
+	// Dialer is used to connect to the proxy itself; a zero net.Dialer is used if nil
+	Dialer *net.Dialer
+
+	// dryRun refuses every dial, for dialers of dry-run clients
+	dryRun bool
+}
+
+// SOCKS5Dialer returns a dialer for the SOCKS5 gateway using this configuration's credentials
+func (p *ProxyConfig) SOCKS5Dialer() *SOCKS5Dialer {
+	return &SOCKS5Dialer{
+		ProxyAddr: net.JoinHostPort(p.Host, strconv.Itoa(p.SOCKS5Port)),
+		Username:  p.Username,
+		Password:  p.Password,
+		LocalDNS:  p.options != nil && p.options.LocalDNS,
+		Dialer:    &net.Dialer{Timeout: p.DialTimeout},
+		dryRun:    p.dryRun(),
+	}
+}
+
+// VerifyRemoteDNS checks that hostnames are resolved by the proxy rather than locally.
+// It connects to hostname through the SOCKS5 gateway while handing the proxy only the
+// unresolved name; a successful CONNECT means the proxy performed the lookup. If hostname
+// is empty, DefaultDNSCheckHost is used.
+func (p *ProxyConfig) VerifyRemoteDNS(ctx context.Context, hostname string) error {
+	if hostname == "" {
+		hostname = DefaultDNSCheckHost
+	}
+	if net.ParseIP(hostname) != nil {
+		return fmt.Errorf("remote DNS check requires a hostname, got IP address %s", hostname)
+	}
+
+	dialer := p.SOCKS5Dialer()
+	if dialer.LocalDNS {
+		return fmt.Errorf("remote DNS is disabled for this configuration (LocalDNS is set)")
+	}
+
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(hostname, "443"))
+	if err != nil {
+		return fmt.Errorf("remote DNS resolution of %s failed: %w", hostname, err)
+	}
+	return conn.Close()
+}
+
+// Dial connects to addr through the SOCKS5 proxy
+func (d *SOCKS5Dialer) Dial(network, addr string) (net.Conn, error) {
+	return d.DialContext(context.Background(), network, addr)
+}
+
+// DialContext connects to addr through the SOCKS5 proxy, respecting context cancellation
+// and deadlines during the handshake
+func (d *SOCKS5Dialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	switch network {
+	case "tcp", "tcp4", "tcp6":
+	default:
+		return nil, fmt.Errorf("socks5: unsupported network %q", network)
+	}
+	if d.dryRun {
+		return nil, fmt.Errorf("socks5: %w", ErrDryRun)
+	}
+
+	dialer := d.Dialer
+	if dialer == nil {
+		dialer = &net.Dialer{}
+	}
+
+	if d.LocalDNS {
+		resolved, err := resolveLocally(ctx, addr)
+		if err != nil {
+			return nil, err
+		}
+		addr = resolved
+	}
+
+	conn, err := dialer.DialContext(ctx, "tcp", d.ProxyAddr)
+	if err != nil {
+		return nil, fmt.Errorf("socks5: failed to connect to proxy: %w", err)
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	// Abort the handshake if the context is cancelled
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.SetDeadline(time.Unix(1, 0))
+		case <-done:
+		}
+	}()
+
+	err = d.handshake(conn, addr)
+	close(done)
+	if err != nil {
+		conn.Close()
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, err
+	}
+
+	conn.SetDeadline(time.Time{})
+	return conn, nil
+}
+
+// resolveLocally replaces the host in addr with its first locally resolved IP address
+func resolveLocally(ctx context.Context, addr string) (string, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", fmt.Errorf("socks5: invalid address %q: %w", addr, err)
+	}
+	if net.ParseIP(host) != nil {
+		return addr, nil
+	}
+
+	ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return "", fmt.Errorf("socks5: failed to resolve %s: %w", host, err)
+	}
+	if len(ips) == 0 {
+		return "", fmt.Errorf("socks5: no addresses found for %s", host)
+	}
+	return net.JoinHostPort(ips[0].IP.String(), port), nil
+}
+
+// handshake negotiates authentication and issues the CONNECT command
+func (d *SOCKS5Dialer) handshake(conn net.Conn, addr string) error {
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("socks5: invalid address %q: %w", addr, err)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port < 1 || port > 65535 {
+		return fmt.Errorf("socks5: invalid port %q", portStr)
+	}
+
+	// Method negotiation
+	method := byte(socks5AuthNone)
+	if d.Username != "" || d.Password != "" {
+		method = socks5AuthPassword
+	}
+	if _, err := conn.Write([]byte{socks5Version, 1, method}); err != nil {
+		return fmt.Errorf("socks5: failed to send greeting: %w", err)
+	}
+
+	reply := make([]byte, 2)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return fmt.Errorf("socks5: failed to read greeting reply: %w", err)
+	}
+	if reply[0] != socks5Version {
+		return fmt.Errorf("socks5: unexpected protocol version %d", reply[0])
+	}
+	if reply[1] == socks5AuthNoAcceptable || reply[1] != method {
+		return fmt.Errorf("socks5: proxy rejected authentication method")
+	}
+
+	// Username/password authentication
+	if method == socks5AuthPassword {
+		if len(d.Username) > 255 || len(d.Password) > 255 {
+			return fmt.Errorf("socks5: username or password longer than 255 bytes")
+		}
+		auth := []byte{socks5AuthVersion, byte(len(d.Username))}
+		auth = append(auth, d.Username...)
+		auth = append(auth, byte(len(d.Password)))
+		auth = append(auth, d.Password...)
+		if _, err := conn.Write(auth); err != nil {
+			return fmt.Errorf("socks5: failed to send credentials: %w", err)
+		}
+		if _, err := io.ReadFull(conn, reply); err != nil {
+			return fmt.Errorf("socks5: failed to read authentication reply: %w", err)
+		}
+		if reply[1] != 0x00 {
+			return fmt.Errorf("socks5: proxy authentication failed")
+		}
+	}
+
+	// CONNECT request
+	req := []byte{socks5Version, socks5CmdConnect, 0x00}
+	if ip := net.ParseIP(host); ip != nil {
+		if ip4 := ip.To4(); ip4 != nil {
+			req = append(req, socks5AddrIPv4)
+			req = append(req, ip4...)
+		} else {
+			req = append(req, socks5AddrIPv6)
+			req = append(req, ip.To16()...)
+		}
+	} else {
+		if len(host) > 255 {
+			return fmt.Errorf("socks5: hostname too long")
+		}
+		req = append(req, socks5AddrDomain, byte(len(host)))
+		req = append(req, host...)
+	}
+	req = append(req, byte(port>>8), byte(port))
+
+	if _, err := conn.Write(req); err != nil {
+		return fmt.Errorf("socks5: failed to send connect request: %w", err)
+	}
+
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return fmt.Errorf("socks5: failed to read connect reply: %w", err)
+	}
+	if header[1] != 0x00 {
+		return fmt.Errorf("socks5: connect to %s failed: %s", addr, socks5ReplyMessage(header[1]))
+	}
+
+	// Discard the bound address
+	var skip int
+	switch header[3] {
+	case socks5AddrIPv4:
+		skip = net.IPv4len
+	case socks5AddrIPv6:
+		skip = net.IPv6len
+	case socks5AddrDomain:
+		length := make([]byte, 1)
+		if _, err := io.ReadFull(conn, length); err != nil {
+			return fmt.Errorf("socks5: failed to read bound address: %w", err)
+		}
+		skip = int(length[0])
+	default:
+		return fmt.Errorf("socks5: unknown address type %d", header[3])
+	}
+	if _, err := io.ReadFull(conn, make([]byte, skip+2)); err != nil {
+		return fmt.Errorf("socks5: failed to read bound address: %w", err)
+	}
+
+	return nil
+}
+
+// socks5ReplyMessage describes a SOCKS5 reply code
+func socks5ReplyMessage(code byte) string {
+	switch code {
+	case 0x01:
+		return "general SOCKS server failure"
+	case 0x02:
+		return "connection not allowed by ruleset"
+	case 0x03:
+		return "network unreachable"
+	case 0x04:
+		return "host unreachable"
+	case 0x05:
+		return "connection refused"
+	case 0x06:
+		return "TTL expired"
+	case 0x07:
+		return "command not supported"
+	case 0x08:
+		return "address type not supported"
+	default:
+		return fmt.Sprintf("unknown error code %d", code)
+	}
+}
@@ -0,0 +1,194 @@
+package nodemaven
+
+import (
+	"context"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// socks5Request is what the test SOCKS5 server received in a CONNECT request
+type socks5Request struct {
+	addrType byte
+	host     string
+	port     int
+}
+
+// socks5TestServer is an in-process SOCKS5 proxy that accepts one username/password pair,
+// records CONNECT requests, answers them with replyCode and then echoes the tunnel
+type socks5TestServer struct {
+	listener  net.Listener
+	username  string
+	password  string
+	replyCode byte
+	requests  chan socks5Request
+}
+
+func newSOCKS5TestServer(t *testing.T, replyCode byte) *socks5TestServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &socks5TestServer{
+		listener:  listener,
+		username:  "test_user-country-de",
+		password:  "pw12345678abc",
+		replyCode: replyCode,
+		requests:  make(chan socks5Request, 8),
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *socks5TestServer) dialer(localDNS bool) *SOCKS5Dialer {
+	return &SOCKS5Dialer{
+		ProxyAddr: s.listener.Addr().String(),
+		Username:  s.username,
+		Password:  s.password,
+		LocalDNS:  localDNS,
+	}
+}
+
+func (s *socks5TestServer) serve(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	// Greeting: version, method count, methods
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return
+	}
+	methods := make([]byte, header[1])
+	if _, err := io.ReadFull(conn, methods); err != nil {
+		return
+	}
+	conn.Write([]byte{socks5Version, socks5AuthPassword})
+
+	// RFC 1929 username/password sub-negotiation
+	readField := func() string {
+		length := make([]byte, 1)
+		if _, err := io.ReadFull(conn, length); err != nil {
+			return ""
+		}
+		field := make([]byte, length[0])
+		io.ReadFull(conn, field)
+		return string(field)
+	}
+	if _, err := io.ReadFull(conn, header[:1]); err != nil {
+		return
+	}
+	username, password := readField(), readField()
+	if username != s.username || password != s.password {
+		conn.Write([]byte{socks5AuthVersion, 0x01})
+		return
+	}
+	conn.Write([]byte{socks5AuthVersion, 0x00})
+
+	// CONNECT request
+	request := make([]byte, 4)
+	if _, err := io.ReadFull(conn, request); err != nil {
+		return
+	}
+	received := socks5Request{addrType: request[3]}
+	switch request[3] {
+	case socks5AddrIPv4, socks5AddrIPv6:
+		ip := make([]byte, net.IPv4len)
+		if request[3] == socks5AddrIPv6 {
+			ip = make([]byte, net.IPv6len)
+		}
+		io.ReadFull(conn, ip)
+		received.host = net.IP(ip).String()
+	case socks5AddrDomain:
+		received.host = readField()
+	}
+	port := make([]byte, 2)
+	io.ReadFull(conn, port)
+	received.port = int(port[0])<<8 | int(port[1])
+	s.requests <- received
+
+	conn.Write([]byte{socks5Version, s.replyCode, 0x00, socks5AddrIPv4, 127, 0, 0, 1, 0x1f, 0x90})
+	if s.replyCode == 0x00 {
+		io.Copy(conn, conn)
+	}
+}
+
+func TestSOCKS5DialerRemoteDNS(t *testing.T) {
+	server := newSOCKS5TestServer(t, 0x00)
+
+	conn, err := server.dialer(false).DialContext(context.Background(), "tcp", "target.example.com:443")
+	if err != nil {
+		t.Fatalf("DialContext: %v", err)
+	}
+	defer conn.Close()
+
+	request := <-server.requests
+	if request.addrType != socks5AddrDomain || request.host != "target.example.com" || request.port != 443 {
+		t.Errorf("CONNECT request = %+v, want the unresolved hostname on port 443", request)
+	}
+
+	// The returned connection is the tunnel
+	if _, err := conn.Write([]byte("ping")); err != nil {
+		t.Fatalf("write through tunnel: %v", err)
+	}
+	echo := make([]byte, 4)
+	if _, err := io.ReadFull(conn, echo); err != nil || string(echo) != "ping" {
+		t.Errorf("tunnel echoed %q, %v", echo, err)
+	}
+}
+
+func TestSOCKS5DialerLocalDNS(t *testing.T) {
+	server := newSOCKS5TestServer(t, 0x00)
+
+	conn, err := server.dialer(true).DialContext(context.Background(), "tcp", "localhost:8080")
+	if err != nil {
+		t.Fatalf("DialContext: %v", err)
+	}
+	conn.Close()
+
+	request := <-server.requests
+	if request.addrType == socks5AddrDomain || net.ParseIP(request.host) == nil || !net.ParseIP(request.host).IsLoopback() {
+		t.Errorf("CONNECT request = %+v, want the locally resolved loopback address", request)
+	}
+	if request.port != 8080 {
+		t.Errorf("port = %d, want 8080", request.port)
+	}
+}
+
+func TestSOCKS5DialerAuthFailure(t *testing.T) {
+	server := newSOCKS5TestServer(t, 0x00)
+	dialer := server.dialer(false)
+	dialer.Password = "wrong"
+
+	_, err := dialer.DialContext(context.Background(), "tcp", "target.example.com:443")
+	if err == nil || !strings.Contains(err.Error(), "authentication failed") {
+		t.Errorf("error = %v, want an authentication failure", err)
+	}
+	select {
+	case request := <-server.requests:
+		t.Errorf("CONNECT sent after failed authentication: %+v", request)
+	default:
+	}
+}
+
+func TestSOCKS5DialerConnectFailure(t *testing.T) {
+	server := newSOCKS5TestServer(t, 0x05)
+
+	addr := net.JoinHostPort("target.example.com", strconv.Itoa(443))
+	_, err := server.dialer(false).DialContext(context.Background(), "tcp", addr)
+	if err == nil || !strings.Contains(err.Error(), "connection refused") || !strings.Contains(err.Error(), addr) {
+		t.Errorf("error = %v, want the connection refused reply for %s", err, addr)
+	}
+}
@@ -0,0 +1,67 @@
+package nodemaven
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// SupportDump returns the account information as indented JSON that is safe to share with
+// support: the proxy password is redacted, while email, plan, usage and status are kept
+func (u *UserInfo) SupportDump() ([]byte, error) {
+	dump := *u
+	if dump.ProxyPassword != "" {
+		dump.ProxyPassword = redacted
+	}
+
+	data, err := json.MarshalIndent(&dump, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal user info: %w", err)
+	}
+	return data, nil
+}
+
+// clientSupportDump is the JSON shape of Client.SupportDump
+type clientSupportDump struct {
+	APIKey              string        `json:"api_key"`
+	BaseURL             string        `json:"base_url"`
+	ProxyHost           string        `json:"proxy_host"`
+	HTTPPort            int           `json:"http_port"`
+	SOCKS5Port          int           `json:"socks5_port"`
+	Timeout             string        `json:"timeout"`
+	MaxRetries          int           `json:"max_retries"`
+	CredentialRetries   int           `json:"credential_retries"`
+	DefaultProxyOptions *ProxyOptions `json:"default_proxy_options,omitempty"`
+	Profiles            []string      `json:"profiles,omitempty"`
+	LastRequestID       string        `json:"last_request_id,omitempty"`
+	UserAgent           string        `json:"user_agent"`
+}
+
+// SupportDump returns the client configuration as indented JSON that is safe to share with
+// support. The API key is reduced to its last four characters.
+func (c *Client) SupportDump() ([]byte, error) {
+	settings := c.settings()
+
+	apiKey := redacted
+	if len(settings.apiKey) > 8 {
+		apiKey = redacted + settings.apiKey[len(settings.apiKey)-4:]
+	}
+
+	data, err := json.MarshalIndent(&clientSupportDump{
+		APIKey:              apiKey,
+		BaseURL:             settings.baseURL,
+		ProxyHost:           settings.proxyHost,
+		HTTPPort:            settings.httpPort,
+		SOCKS5Port:          settings.socks5Port,
+		Timeout:             c.Timeout.String(),
+		MaxRetries:          c.MaxRetries,
+		CredentialRetries:   c.CredentialRetries,
+		DefaultProxyOptions: c.DefaultProxyOptions,
+		Profiles:            c.ProfileNames(),
+		LastRequestID:       c.LastRequestID(),
+		UserAgent:           UserAgent,
+	}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal client config: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,87 @@
+package nodemaven
+
+import (
+	"context"
+	"errors"
+)
+
+// TargetingBuilder assembles country/region/city targeting, validating every value against
+// a live location tree as it is set so mistakes surface on the offending call
+type TargetingBuilder struct {
+	tree    *LocationTree
+	options ProxyOptions
+}
+
+// NewTargeting loads the location tree (cached on the client, see BuildLocationTree) for the
+// connection type in DefaultProxyOptions, DefaultConnectionType if unset, and returns a builder over it.
+//
+// If only some regions or cities could be fetched, the builder over the partial tree is returned
+// together with the *LocationTreeError; targeting inside the missing parts is then rejected.
+func (c *Client) NewTargeting(ctx context.Context) (*TargetingBuilder, error) {
+	connectionType := c.defaultConnectionType()
+	if c.DefaultProxyOptions != nil && c.DefaultProxyOptions.ConnectionType != "" {
+		connectionType = c.DefaultProxyOptions.ConnectionType
+	}
+
+	tree, err := c.BuildLocationTree(ctx, connectionType)
+	var treeErr *LocationTreeError
+	if err != nil && !(errors.As(err, &treeErr) && tree != nil) {
+		return nil, err
+	}
+	return NewTargetingBuilder(tree), err
+}
+
+// NewTargetingBuilder returns a builder validating against an already loaded tree
+func NewTargetingBuilder(tree *LocationTree) *TargetingBuilder {
+	b := &TargetingBuilder{tree: tree}
+	if tree.ConnectionType != ConnectionTypeResidential {
+		b.options.ConnectionType = tree.ConnectionType
+	}
+	return b
+}
+
+// Country sets the target country, clearing any region and city targeting. Unknown
+// countries return a *TargetingError and leave the builder unchanged.
+func (b *TargetingBuilder) Country(code string) error {
+	country := b.tree.Country(code)
+	if country == nil {
+		return &TargetingError{Level: "country", Value: code}
+	}
+
+	b.options.Country = country.Code
+	b.options.Region, b.options.Regions = "", nil
+	b.options.City, b.options.Cities = "", nil
+	return nil
+}
+
+// Region sets the target region, clearing any city targeting. The region must exist (in
+// the target country, if one is set); otherwise a *TargetingError is returned and the
+// builder is left unchanged.
+func (b *TargetingBuilder) Region(region string) error {
+	candidate := b.options
+	candidate.Region, candidate.Regions = region, nil
+	candidate.City, candidate.Cities = "", nil
+	return b.apply(&candidate)
+}
+
+// City sets the target city. The city must exist within the targeted region or country;
+// otherwise a *TargetingError is returned and the builder is left unchanged.
+func (b *TargetingBuilder) City(city string) error {
+	candidate := b.options
+	candidate.City, candidate.Cities = city, nil
+	return b.apply(&candidate)
+}
+
+// apply validates candidate against the tree and adopts it on success
+func (b *TargetingBuilder) apply(candidate *ProxyOptions) error {
+	if err := ValidateTargeting(b.tree, candidate); err != nil {
+		return err
+	}
+	b.options = *candidate
+	return nil
+}
+
+// Build returns the validated targeting as proxy options
+func (b *TargetingBuilder) Build() *ProxyOptions {
+	return copyProxyOptions(&b.options)
+}
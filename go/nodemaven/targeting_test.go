@@ -0,0 +1,50 @@
+package nodemaven
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+// TestNewTargetingPartialTree checks that a failed region fetch still yields a usable builder
+func TestNewTargetingPartialTree(t *testing.T) {
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case EndpointCountries:
+			w.Write([]byte(`{"count": 2, "next": null, "previous": null, "results": [
+				{"id": "1", "name": "Germany", "code": "de"}, {"id": "2", "name": "France", "code": "fr"}]}`))
+		case EndpointRegions:
+			if r.URL.Query().Get("country__code") == "fr" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"detail": "regions unavailable"}`))
+				return
+			}
+			w.Write([]byte(`{"count": 1, "next": null, "previous": null, "results": [
+				{"id": "10", "name": "Bavaria", "code": "bavaria", "country_code": "de"}]}`))
+		case EndpointCities:
+			w.Write([]byte(`{"count": 1, "next": null, "previous": null, "results": [
+				{"id": "100", "name": "Munich", "code": "munich", "country_code": "de", "region_code": "bavaria"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	builder, err := client.NewTargeting(context.Background())
+	var treeErr *LocationTreeError
+	if !errors.As(err, &treeErr) {
+		t.Fatalf("NewTargeting error = %v, want a *LocationTreeError", err)
+	}
+	if builder == nil {
+		t.Fatal("NewTargeting returned no builder for the partial tree")
+	}
+	if err := builder.Country("de"); err != nil {
+		t.Errorf("Country(de): %v", err)
+	}
+	if err := builder.Region("bavaria"); err != nil {
+		t.Errorf("Region(bavaria): %v", err)
+	}
+	if err := builder.Country("fr"); err != nil {
+		t.Errorf("Country(fr): %v", err)
+	}
+}
@@ -0,0 +1,114 @@
+package nodemaven
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// ProxyTiming breaks down the latency of one request made through the proxy. Phases that did
+// not happen are zero: DNS when the gateway host is an IP address, TLSHandshake for plain
+// HTTP targets. Target hostnames are resolved by the gateway, so their lookup is part of
+// FirstByte rather than DNS.
+type ProxyTiming struct {
+	// DNS is the lookup of the proxy gateway host
+	DNS time.Duration
+	// Connect is the TCP connection to the proxy gateway
+	Connect time.Duration
+	// TLSHandshake is the handshake with the target, made through the tunnel for HTTPS targets
+	TLSHandshake time.Duration
+	// FirstByte is the time from the start of the request to the first response byte (TTFB)
+	FirstByte time.Duration
+	// Total is the time from the start of the request until the body was fully read
+	Total      time.Duration
+	StatusCode int
+}
+
+// MeasureTiming requests targetURL through the proxy over a new connection and reports how
+// long each phase took, to pinpoint where proxy latency comes from. The response body is
+// read and discarded. Any status code counts as a measurement; only transport failures
+// return an error.
+func (p *ProxyConfig) MeasureTiming(ctx context.Context, targetURL string) (*ProxyTiming, error) {
+	transport := p.Transport()
+	transport.DisableKeepAlives = true
+	defer transport.CloseIdleConnections()
+
+	// The trace callbacks may run concurrently, e.g. ConnectStart/ConnectDone for each address
+	// of a dual-stack dial, and may still fire after the request returned, so mu guards all of
+	// them. Only the first dial attempt and the first successful connection are recorded.
+	var (
+		mu                               sync.Mutex
+		timing                           ProxyTiming
+		dnsStart, connectStart, tlsStart time.Time
+	)
+	clk := p.getClock()
+	since := func(t time.Time) time.Duration { return clk.Now().Sub(t) }
+	locked := func(fn func()) {
+		mu.Lock()
+		defer mu.Unlock()
+		fn()
+	}
+	start := clk.Now()
+	trace := &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { locked(func() { dnsStart = clk.Now() }) },
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			locked(func() {
+				if !dnsStart.IsZero() {
+					timing.DNS = since(dnsStart)
+				}
+			})
+		},
+		ConnectStart: func(string, string) {
+			locked(func() {
+				if connectStart.IsZero() {
+					connectStart = clk.Now()
+				}
+			})
+		},
+		ConnectDone: func(_, _ string, err error) {
+			locked(func() {
+				if err == nil && !connectStart.IsZero() && timing.Connect == 0 {
+					timing.Connect = since(connectStart)
+				}
+			})
+		},
+		TLSHandshakeStart: func() { locked(func() { tlsStart = clk.Now() }) },
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			locked(func() {
+				if !tlsStart.IsZero() {
+					timing.TLSHandshake = since(tlsStart)
+				}
+			})
+		},
+		GotFirstResponseByte: func() { locked(func() { timing.FirstByte = since(start) }) },
+	}
+
+	req, err := http.NewRequestWithContext(httptrace.WithClientTrace(ctx, trace), "GET", targetURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", UserAgent)
+
+	client := &http.Client{Transport: transport, Timeout: p.client.Timeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request through proxy failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if _, err := io.Copy(io.Discard, resp.Body); err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	result := timing
+	result.Total = since(start)
+	result.StatusCode = resp.StatusCode
+	return &result, nil
+}
@@ -0,0 +1,32 @@
+package nodemaven
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestMeasureTiming(t *testing.T) {
+	client := newGatewayTestClient(t, &Config{}, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+		w.Write([]byte("short and stout"))
+	})
+	proxyConfig, err := client.GetProxyConfig(nil)
+	if err != nil {
+		t.Fatalf("GetProxyConfig: %v", err)
+	}
+
+	timing, err := proxyConfig.MeasureTiming(context.Background(), "http://example.com/")
+	if err != nil {
+		t.Fatalf("MeasureTiming: %v", err)
+	}
+	if timing.StatusCode != http.StatusTeapot {
+		t.Errorf("StatusCode = %d, want %d", timing.StatusCode, http.StatusTeapot)
+	}
+	if timing.Connect <= 0 || timing.FirstByte < timing.Connect || timing.Total < timing.FirstByte {
+		t.Errorf("inconsistent phases: %+v", timing)
+	}
+	if timing.TLSHandshake != 0 {
+		t.Errorf("TLSHandshake = %v for a plain HTTP target", timing.TLSHandshake)
+	}
+}
@@ -0,0 +1,148 @@
+package nodemaven
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultTrafficThreshold is the percentage of the traffic limit at which a TrafficGuard blocks requests
+	DefaultTrafficThreshold = 95.0
+	// DefaultTrafficRefreshInterval is how long a TrafficGuard trusts its cached usage
+	DefaultTrafficRefreshInterval = time.Minute
+)
+
+// TrafficGuard is an http.RoundTripper that refuses new requests with a
+// *TrafficLimitExceededError once the account's traffic usage crosses ThresholdPercent of
+// the plan limit, as a safety valve against overage charges. Usage is read from cached
+// UserInfo that is refreshed every RefreshInterval. Plans without a limit are never blocked.
+type TrafficGuard struct {
+	Client *Client
+	// Base performs the requests; http.DefaultTransport is used if nil
+	Base http.RoundTripper
+	// ThresholdPercent defaults to DefaultTrafficThreshold
+	ThresholdPercent float64
+	// RefreshInterval defaults to DefaultTrafficRefreshInterval
+	RefreshInterval time.Duration
+
+	mu        sync.Mutex
+	userInfo  *UserInfo
+	fetchedAt time.Time
+	// refreshing is closed when the fetch in progress completes; nil when none is running
+	refreshing chan struct{}
+	fetchErr   error
+}
+
+// NewTrafficGuard wraps base with traffic-limit blocking for client's account
+func NewTrafficGuard(client *Client, base http.RoundTripper, thresholdPercent float64, refreshInterval time.Duration) *TrafficGuard {
+	return &TrafficGuard{
+		Client:           client,
+		Base:             base,
+		ThresholdPercent: thresholdPercent,
+		RefreshInterval:  refreshInterval,
+	}
+}
+
+// HTTPClientWithTrafficGuard returns an HTTP client like HTTPClient whose requests are
+// refused once traffic usage crosses thresholdPercent of the plan limit
+func (p *ProxyConfig) HTTPClientWithTrafficGuard(thresholdPercent float64, refreshInterval time.Duration) *http.Client {
+	client := p.HTTPClient()
+	client.Transport = NewTrafficGuard(p.client, client.Transport, thresholdPercent, refreshInterval)
+	return client
+}
+
+func (g *TrafficGuard) RoundTrip(req *http.Request) (*http.Response, error) {
+	userInfo, err := g.usage(req)
+	if err != nil {
+		return nil, err
+	}
+
+	threshold := g.ThresholdPercent
+	if threshold <= 0 {
+		threshold = DefaultTrafficThreshold
+	}
+	if userInfo.TrafficLimit > 0 &&
+		float64(userInfo.TrafficUsed) >= float64(userInfo.TrafficLimit)*threshold/100 {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, &TrafficLimitExceededError{
+			TrafficUsed:      userInfo.TrafficUsed,
+			TrafficLimit:     userInfo.TrafficLimit,
+			ThresholdPercent: threshold,
+		}
+	}
+
+	base := g.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(req)
+}
+
+// usage returns the cached UserInfo, refreshing it when it is older than RefreshInterval.
+// Refreshes run without holding the lock and concurrent requests share one fetch; while a
+// cached value exists it keeps being served during the refresh, which then runs in the
+// background. If a refresh fails the previous value is kept until the next interval;
+// without one the error is returned.
+func (g *TrafficGuard) usage(req *http.Request) (*UserInfo, error) {
+	interval := g.RefreshInterval
+	if interval <= 0 {
+		interval = DefaultTrafficRefreshInterval
+	}
+
+	g.mu.Lock()
+	now := g.Client.getClock().Now()
+	cached := g.userInfo
+	if cached != nil && now.Sub(g.fetchedAt) < interval {
+		g.mu.Unlock()
+		return cached, nil
+	}
+
+	done := g.refreshing
+	if done == nil {
+		done = make(chan struct{})
+		g.refreshing = done
+		if cached != nil {
+			g.mu.Unlock()
+			go g.refresh(context.Background(), now)
+			return cached, nil
+		}
+		g.mu.Unlock()
+		g.refresh(req.Context(), now)
+	} else {
+		g.mu.Unlock()
+		if cached != nil {
+			return cached, nil
+		}
+		select {
+		case <-done:
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.userInfo == nil {
+		return nil, g.fetchErr
+	}
+	return g.userInfo, nil
+}
+
+// refresh fetches the account usage started at now and wakes the requests waiting for it
+func (g *TrafficGuard) refresh(ctx context.Context, now time.Time) {
+	userInfo, err := g.Client.GetUserInfo(ctx)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if err == nil {
+		g.userInfo = userInfo
+	}
+	g.fetchErr = err
+	g.fetchedAt = now
+	close(g.refreshing)
+	g.refreshing = nil
+}
@@ -0,0 +1,82 @@
+package nodemaven
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// roundTripperFunc adapts a function to http.RoundTripper
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+// TestTrafficGuardServesCacheDuringRefresh checks that a slow usage refresh does not stall requests
+func TestTrafficGuardServesCacheDuringRefresh(t *testing.T) {
+	var (
+		used    atomic.Int64
+		fetches atomic.Int32
+	)
+	release := make(chan struct{})
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if fetches.Add(1) > 1 {
+			<-release
+		}
+		fmt.Fprintf(w, `{"id": "u1", "proxy_username": "test_user", "proxy_password": "pw12345678abc",
+			"traffic_used": %d, "traffic_limit": 1000, "is_active": true}`, used.Load())
+	})
+	clk := newFakeClock(time.Now())
+	client.clock = clk
+
+	ok := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+	})
+	guard := NewTrafficGuard(client, ok, 90, time.Minute)
+	do := func() error {
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, "http://example.com/", nil)
+		resp, err := guard.RoundTrip(req)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	if err := do(); err != nil {
+		t.Fatalf("first request: %v", err)
+	}
+
+	// The refresh blocks on the server, yet requests keep using the cached usage meanwhile
+	used.Store(950)
+	clk.Advance(2 * time.Minute)
+	for i := 0; i < 3; i++ {
+		done := make(chan error, 1)
+		go func() { done <- do() }()
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatalf("request during refresh: %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("request stalled behind the usage refresh")
+		}
+	}
+	close(release)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		err := do()
+		if _, blocked := err.(*TrafficLimitExceededError); blocked {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("refreshed usage was not applied, last error: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := fetches.Load(); n != 2 {
+		t.Errorf("usage fetched %d times, want 2", n)
+	}
+}
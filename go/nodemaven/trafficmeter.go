@@ -0,0 +1,130 @@
+package nodemaven
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// JobTraffic is the traffic attributed to one job by a TrafficMeter
+type JobTraffic struct {
+	Requests      int64
+	BytesSent     int64
+	BytesReceived int64
+}
+
+// jobKey is the context key carrying the job label
+type jobKey struct{}
+
+// WithJob labels requests made with the returned context, so a TrafficMeter attributes
+// their traffic to job
+func WithJob(ctx context.Context, job string) context.Context {
+	return context.WithValue(ctx, jobKey{}, job)
+}
+
+// TrafficMeter is an http.RoundTripper that counts the body bytes sent and received per job,
+// as labelled with WithJob (unlabelled requests count under ""). The API only reports
+// account-wide traffic; the meter attributes it client-side. Headers and TLS overhead are
+// not counted, so totals are slightly below what the gateway bills.
+type TrafficMeter struct {
+	// Base performs the requests; http.DefaultTransport is used if nil
+	Base http.RoundTripper
+
+	mu   sync.Mutex
+	jobs map[string]*JobTraffic
+}
+
+// NewTrafficMeter wraps base with per-job traffic accounting
+func NewTrafficMeter(base http.RoundTripper) *TrafficMeter {
+	return &TrafficMeter{Base: base}
+}
+
+func (m *TrafficMeter) RoundTrip(req *http.Request) (*http.Response, error) {
+	job, _ := req.Context().Value(jobKey{}).(string)
+	counters := m.counters(job)
+	atomic.AddInt64(&counters.Requests, 1)
+
+	if req.Body != nil && req.Body != http.NoBody {
+		clone := req.Clone(req.Context())
+		clone.Body = &countingReadCloser{ReadCloser: req.Body, count: &counters.BytesSent}
+		req = clone
+	}
+
+	base := m.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = &countingReadCloser{ReadCloser: resp.Body, count: &counters.BytesReceived}
+	return resp, nil
+}
+
+// Usage returns the traffic counted so far for job
+func (m *TrafficMeter) Usage(job string) JobTraffic {
+	m.mu.Lock()
+	counters, ok := m.jobs[job]
+	m.mu.Unlock()
+	if !ok {
+		return JobTraffic{}
+	}
+	return counters.snapshot()
+}
+
+// Jobs returns the traffic counted so far for every job
+func (m *TrafficMeter) Jobs() map[string]JobTraffic {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	jobs := make(map[string]JobTraffic, len(m.jobs))
+	for job, counters := range m.jobs {
+		jobs[job] = counters.snapshot()
+	}
+	return jobs
+}
+
+// Reset clears the counters of job
+func (m *TrafficMeter) Reset(job string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.jobs, job)
+}
+
+// counters returns the counters of job, creating them on first use
+func (m *TrafficMeter) counters(job string) *JobTraffic {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.jobs == nil {
+		m.jobs = make(map[string]*JobTraffic)
+	}
+	counters, ok := m.jobs[job]
+	if !ok {
+		counters = &JobTraffic{}
+		m.jobs[job] = counters
+	}
+	return counters
+}
+
+// snapshot reads the counters atomically
+func (t *JobTraffic) snapshot() JobTraffic {
+	return JobTraffic{
+		Requests:      atomic.LoadInt64(&t.Requests),
+		BytesSent:     atomic.LoadInt64(&t.BytesSent),
+		BytesReceived: atomic.LoadInt64(&t.BytesReceived),
+	}
+}
+
+// countingReadCloser adds the number of bytes read to count
+type countingReadCloser struct {
+	io.ReadCloser
+	count *int64
+}
+
+func (c *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	atomic.AddInt64(c.count, int64(n))
+	return n, err
+}
@@ -2,8 +2,19 @@ package nodemaven
 
 import (
 	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net"
 	"net/http"
 	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -19,6 +30,88 @@ type UserInfo struct {
 	SubscriptionType string `json:"subscription_type"`
 	IsActive         bool   `json:"is_active"`
 	DateJoined       string `json:"date_joined"`
+	// MaxConcurrency is the plan's concurrent connection limit, or 0 if not reported
+	MaxConcurrency int `json:"max_concurrency"`
+	// ProxyHost and the proxy ports describe an account-specific gateway, when the API reports one
+	ProxyHost       string `json:"proxy_host"`
+	ProxyHTTPPort   int    `json:"proxy_http_port"`
+	ProxySOCKS5Port int    `json:"proxy_socks5_port"`
+	// Ports lists extra gateway ports (e.g. per protocol), when the API reports them
+	Ports []PortInfo `json:"proxy_ports"`
+
+	// inactive is set when the API explicitly reports is_active false
+	inactive bool
+}
+
+// PortInfo describes a proxy gateway port available to the account
+type PortInfo struct {
+	Port        int    `json:"port"`
+	Protocol    string `json:"protocol"`
+	Description string `json:"description"`
+}
+
+// AvailablePorts lists the gateway ports of the account. When the API reports none, the
+// account's HTTP and SOCKS5 ports are listed, falling back to DefaultHTTPPort and DefaultSOCKS5Port.
+func (u *UserInfo) AvailablePorts() []PortInfo {
+	if len(u.Ports) > 0 {
+		return append([]PortInfo(nil), u.Ports...)
+	}
+	return []PortInfo{
+		{Port: u.portFor("http", DefaultHTTPPort), Protocol: "http", Description: "HTTP/HTTPS proxy"},
+		{Port: u.portFor("socks5", DefaultSOCKS5Port), Protocol: "socks5", Description: "SOCKS5 proxy"},
+	}
+}
+
+// portFor returns the account's port for protocol ("http" or "socks5"), or fallback if none is reported
+func (u *UserInfo) portFor(protocol string, fallback int) int {
+	switch protocol {
+	case "http":
+		if u.ProxyHTTPPort > 0 {
+			return u.ProxyHTTPPort
+		}
+	case "socks5":
+		if u.ProxySOCKS5Port > 0 {
+			return u.ProxySOCKS5Port
+		}
+	}
+	for _, port := range u.Ports {
+		if strings.EqualFold(port.Protocol, protocol) && port.Port > 0 {
+			return port.Port
+		}
+	}
+	return fallback
+}
+
+// UnmarshalJSON decodes user info, accepting the traffic counters as numbers, floats or strings,
+// and records whether is_active was explicitly false
+func (u *UserInfo) UnmarshalJSON(data []byte) error {
+	type plain UserInfo
+	aux := struct {
+		*plain
+		TrafficUsed  flexInt64 `json:"traffic_used"`
+		TrafficLimit flexInt64 `json:"traffic_limit"`
+		IsActive     *bool     `json:"is_active"`
+	}{plain: (*plain)(u)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	u.TrafficUsed = int64(aux.TrafficUsed)
+	u.TrafficLimit = int64(aux.TrafficLimit)
+	u.IsActive = aux.IsActive != nil && *aux.IsActive
+	u.inactive = aux.IsActive != nil && !*aux.IsActive
+	return nil
+}
+
+// DefaultPoolSize is the recommended number of concurrent sessions when the plan limit is unknown
+const DefaultPoolSize = 10
+
+// RecommendedPoolSize returns how many concurrent proxy sessions to use for this account,
+// capped by the plan's MaxConcurrency when it is reported
+func (u *UserInfo) RecommendedPoolSize() int {
+	if u.MaxConcurrency > 0 {
+		return u.MaxConcurrency
+	}
+	return DefaultPoolSize
 }
 
 // Country represents a country location
@@ -60,11 +153,121 @@ type City struct {
 // StatisticEntry represents a single statistics entry
 type StatisticEntry struct {
 	Date        string  `json:"date"`
+	Country     string  `json:"country,omitempty"`
 	TrafficUsed int64   `json:"traffic_used"`
 	Requests    int     `json:"requests"`
 	SuccessRate float64 `json:"success_rate"`
 }
 
+// UnmarshalJSON decodes a statistics entry, accepting TrafficUsed as a number, float or string
+func (e *StatisticEntry) UnmarshalJSON(data []byte) error {
+	type plain StatisticEntry
+	aux := struct {
+		*plain
+		TrafficUsed flexInt64 `json:"traffic_used"`
+	}{plain: (*plain)(e)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	e.TrafficUsed = int64(aux.TrafficUsed)
+	return nil
+}
+
+// flexInt64 decodes an integer sent as a JSON number, a float or a numeric string
+type flexInt64 int64
+
+func (f *flexInt64) UnmarshalJSON(data []byte) error {
+	raw := strings.Trim(string(data), `"`)
+	if raw == "" || raw == "null" {
+		*f = 0
+		return nil
+	}
+	if value, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		*f = flexInt64(value)
+		return nil
+	}
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return fmt.Errorf("invalid numeric value %s", data)
+	}
+	// float64(math.MaxInt64) rounds up to 2^63, which no longer fits
+	rounded := math.Round(value)
+	if rounded < math.MinInt64 || rounded >= math.MaxInt64 {
+		return fmt.Errorf("numeric value %s is out of the int64 range", data)
+	}
+	*f = flexInt64(rounded)
+	return nil
+}
+
+// ParsedDate parses the entry's date, given in the dd-mm-yyyy statistics format
+// (yyyy-mm-dd is accepted too)
+func (e StatisticEntry) ParsedDate() (time.Time, error) {
+	if date, err := time.Parse(statisticsDateFormat, e.Date); err == nil {
+		return date, nil
+	}
+	date, err := time.Parse("2006-01-02", e.Date)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid statistics date %q", e.Date)
+	}
+	return date, nil
+}
+
+// UsageProjection represents a traffic usage forecast based on recent statistics
+type UsageProjection struct {
+	TrafficUsed    int64         `json:"traffic_used"`
+	TrafficLimit   int64         `json:"traffic_limit"`
+	RemainingBytes int64         `json:"remaining_bytes"`
+	DailyAverage   int64         `json:"daily_average"`
+	Window         time.Duration `json:"window"`
+	ProjectedBytes int64         `json:"projected_bytes"`
+	WillExceed     bool          `json:"will_exceed"`
+	ExhaustionDate time.Time     `json:"exhaustion_date"`
+}
+
+// UsageSummary is a point-in-time snapshot of account usage
+type UsageSummary struct {
+	TrafficUsed  int64 `json:"traffic_used"`
+	TrafficLimit int64 `json:"traffic_limit"`
+	Requests     int   `json:"requests"`
+	// SuccessRate is the request-weighted success rate (percent) over the statistics period
+	SuccessRate float64   `json:"success_rate"`
+	CapturedAt  time.Time `json:"captured_at"`
+}
+
+// UsageDelta is the change in usage between two UsageSummary snapshots
+type UsageDelta struct {
+	TrafficUsed       int64         `json:"traffic_used"`
+	Requests          int           `json:"requests"`
+	SuccessRateChange float64       `json:"success_rate_change"`
+	Elapsed           time.Duration `json:"elapsed"`
+	// Reset is true when the later snapshot reports less usage than the earlier one, which
+	// happens when the plan renews; the deltas then count usage since the reset
+	Reset bool `json:"reset"`
+}
+
+// IPDetails describes an IP address as reported by the ip-api.com geolocation service
+type IPDetails struct {
+	IP          string   `json:"query"`
+	Status      string   `json:"status"`
+	Country     string   `json:"country"`
+	CountryCode string   `json:"countryCode"`
+	Region      string   `json:"region"`
+	RegionName  string   `json:"regionName"`
+	City        string   `json:"city"`
+	Zip         string   `json:"zip"`
+	Latitude    *float64 `json:"lat"`
+	Longitude   *float64 `json:"lon"`
+	Timezone    string   `json:"timezone"`
+	ISP         string   `json:"isp"`
+	Org         string   `json:"org"`
+	AS          string   `json:"as"`
+}
+
+// HasCoordinates reports whether latitude and longitude are known
+func (d *IPDetails) HasCoordinates() bool {
+	return d != nil && d.Latitude != nil && d.Longitude != nil
+}
+
 // Request and Response types
 
 // CountriesRequest represents a request for countries
@@ -126,6 +329,13 @@ type StatisticsRequest struct {
 	StartDate string `json:"start_date,omitempty"`
 	EndDate   string `json:"end_date,omitempty"`
 	GroupBy   string `json:"group_by"`
+	Limit     int    `json:"limit,omitempty"`
+	Offset    int    `json:"offset,omitempty"`
+	// Country restricts the statistics to traffic exiting in one country, if the API supports it
+	Country string `json:"country,omitempty"`
+	// AllowPartial makes GetAllStatistics return the entries collected so far
+	// (wrapped in a PartialResultsError) when a later page fails
+	AllowPartial bool `json:"-"`
 }
 
 // StatisticsResponse represents the response for statistics
@@ -136,6 +346,52 @@ type StatisticsResponse struct {
 	Results  []StatisticEntry `json:"results"`
 }
 
+// FilledEntries returns one entry per day from start to end (inclusive, by calendar date),
+// inserting zero-valued entries for days the API omitted, so charts have no gaps.
+// Entries outside the range or with unparseable dates are left out.
+func (r *StatisticsResponse) FilledEntries(start, end time.Time) []StatisticEntry {
+	byDate := make(map[string]StatisticEntry, len(r.Results))
+	for _, entry := range r.Results {
+		date, err := entry.ParsedDate()
+		if err != nil {
+			continue
+		}
+		key := date.Format(statisticsDateFormat)
+		if existing, ok := byDate[key]; ok {
+			entry = mergeStatisticEntries(existing, entry)
+		}
+		entry.Date = key
+		byDate[key] = entry
+	}
+
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	last := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC)
+
+	var filled []StatisticEntry
+	for ; !day.After(last); day = day.AddDate(0, 0, 1) {
+		key := day.Format(statisticsDateFormat)
+		if entry, ok := byDate[key]; ok {
+			filled = append(filled, entry)
+		} else {
+			filled = append(filled, StatisticEntry{Date: key})
+		}
+	}
+	return filled
+}
+
+// mergeStatisticEntries combines two entries for the same day, weighting success rates by requests
+func mergeStatisticEntries(a, b StatisticEntry) StatisticEntry {
+	merged := StatisticEntry{
+		Date:        a.Date,
+		TrafficUsed: a.TrafficUsed + b.TrafficUsed,
+		Requests:    a.Requests + b.Requests,
+	}
+	if merged.Requests > 0 {
+		merged.SuccessRate = (a.SuccessRate*float64(a.Requests) + b.SuccessRate*float64(b.Requests)) / float64(merged.Requests)
+	}
+	return merged
+}
+
 // ProxyOptions represents proxy targeting options
 type ProxyOptions struct {
 	Country        string `json:"country,omitempty"`
@@ -149,53 +405,132 @@ type ProxyOptions struct {
 	Protocol       string `json:"protocol,omitempty"`
 	OS             string `json:"os,omitempty"`
 	Browser        string `json:"browser,omitempty"`
+	// Regions and Cities target any of several locations; they cannot be combined
+	// with the singular Region and City fields
+	Regions []string `json:"regions,omitempty"`
+	Cities  []string `json:"cities,omitempty"`
+	// LocalDNS resolves target hostnames locally before handing them to the SOCKS5 proxy
+	// (socks5 scheme). By default hostnames are resolved by the proxy (socks5h scheme),
+	// which keeps DNS lookups from leaking outside the proxy. When set in default options,
+	// per-call options cannot turn it off (see MergeProxyOptions).
+	LocalDNS bool `json:"local_dns,omitempty"`
+	// AutoSession generates a sticky session ID when Session is empty; read it back
+	// with ProxyConfig.Session. Like LocalDNS, per-call options cannot turn off a default.
+	AutoSession bool `json:"auto_session,omitempty"`
 }
 
 // ProxyConfig represents a proxy configuration for HTTP/HTTPS usage
 type ProxyConfig struct {
-	Host     string
-	HTTPPort int
-	Username string
-	Password string
-	client   *Client
-	options  *ProxyOptions
+	Host       string
+	HTTPPort   int
+	SOCKS5Port int
+	Username   string
+	Password   string
+	// DisableHTTP2 restricts transports to HTTP/1.1. By default HTTP/2 is negotiated with
+	// HTTPS targets inside the CONNECT tunnel; plain HTTP requests always use HTTP/1.1
+	// to the gateway, so the gateway itself never needs to speak HTTP/2.
+	DisableHTTP2 bool
+	// DialTimeout bounds connecting to the gateway, separately from the overall request
+	// timeout (Client.Timeout or HTTPClientWithTimeout); 0 leaves dialing unbounded
+	DialTimeout time.Duration
+	client      *Client
+	options     *ProxyOptions
+
+	// mu guards the transport shared by HTTPClient and warmed by Warmup
+	mu        sync.Mutex
+	transport *http.Transport
+	warm      *warmConnPool
 }
 
-// HTTPClient returns an HTTP client configured to use the proxy
-func (p *ProxyConfig) HTTPClient() *http.Client {
-	proxyURL, _ := url.Parse(p.ProxyURL())
+// getClock returns the time source of the client that created the configuration
+func (p *ProxyConfig) getClock() clock {
+	if p.client == nil {
+		return systemClock
+	}
+	return p.client.getClock()
+}
 
-	transport := &http.Transport{
-		Proxy: http.ProxyURL(proxyURL),
+// Session returns the sticky session ID the configuration uses, or "" for a rotating IP
+func (p *ProxyConfig) Session() string {
+	if p.options == nil {
+		return ""
 	}
+	return p.options.Session
+}
 
+// HTTPClient returns an HTTP client configured to use the proxy. Clients returned by
+// HTTPClient and HTTPClientWithTimeout share one transport per ProxyConfig, so they reuse
+// connections, including those opened by Warmup.
+func (p *ProxyConfig) HTTPClient() *http.Client {
 	return &http.Client{
-		Transport: transport,
+		Transport: p.sharedTransport(),
 		Timeout:   p.client.Timeout,
 	}
 }
 
-// HTTPClientWithTimeout returns an HTTP client with custom timeout
-func (p *ProxyConfig) HTTPClientWithTimeout(timeout time.Duration) *http.Client {
+// proxyIdleConnTimeout closes gateway connections left idle, as http.DefaultTransport does,
+// so transports replaced after a credential refresh do not keep connections open forever
+const proxyIdleConnTimeout = 90 * time.Second
+
+// Transport returns a new *http.Transport that routes requests through the proxy, for
+// plugging into a custom http.Client. Each call returns a fresh transport. An HTTPS request
+// whose CONNECT the gateway rejects with 407 fails with an error matching ErrProxyAuthRequired.
+func (p *ProxyConfig) Transport() *http.Transport {
 	proxyURL, _ := url.Parse(p.ProxyURL())
 
 	transport := &http.Transport{
-		Proxy: http.ProxyURL(proxyURL),
+		Proxy:                  http.ProxyURL(proxyURL),
+		ForceAttemptHTTP2:      !p.DisableHTTP2,
+		IdleConnTimeout:        proxyIdleConnTimeout,
+		OnProxyConnectResponse: checkProxyConnectResponse,
+	}
+	if p.DialTimeout > 0 {
+		transport.DialContext = (&net.Dialer{Timeout: p.DialTimeout}).DialContext
+	}
+	if p.DisableHTTP2 {
+		// A non-nil, empty TLSNextProto turns off HTTP/2 negotiation
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+	if p.dryRun() {
+		dryRunProtocols(transport)
 	}
+	return transport
+}
+
+// HTTPClientWithJar returns an HTTP client like HTTPClient that stores cookies in jar.
+// Pair the jar with a sticky session (see Session.HTTPClient) so cookies and exit IP stay consistent.
+func (p *ProxyConfig) HTTPClientWithJar(jar http.CookieJar) *http.Client {
+	client := p.HTTPClient()
+	client.Jar = jar
+	return client
+}
+
+// HTTPClientInsecure returns an HTTP client like HTTPClient that does NOT verify TLS
+// certificates of the sites it connects to.
+//
+// WARNING: this disables protection against man-in-the-middle attacks and must only be used
+// to debug certificate problems (e.g. corporate TLS interception). Never use it in production.
+func (p *ProxyConfig) HTTPClientInsecure() *http.Client {
+	transport := p.Transport()
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
 
 	return &http.Client{
 		Transport: transport,
+		Timeout:   p.client.Timeout,
+	}
+}
+
+// HTTPClientWithTimeout returns an HTTP client with custom timeout
+func (p *ProxyConfig) HTTPClientWithTimeout(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Transport: p.sharedTransport(),
 		Timeout:   timeout,
 	}
 }
 
 // HTTPClientWithContext returns an HTTP client that respects context cancellation
 func (p *ProxyConfig) HTTPClientWithContext(ctx context.Context) *http.Client {
-	proxyURL, _ := url.Parse(p.ProxyURL())
-
-	transport := &http.Transport{
-		Proxy: http.ProxyURL(proxyURL),
-	}
+	transport := p.Transport()
 
 	client := &http.Client{
 		Transport: transport,
@@ -211,6 +546,29 @@ func (p *ProxyConfig) HTTPClientWithContext(ctx context.Context) *http.Client {
 	return client
 }
 
+// HTTPClientWithBypass returns an HTTP client that sends requests for hosts matching
+// any of the bypass patterns directly and everything else through the proxy.
+// Patterns are exact hostnames ("api.example.com"), wildcards matching subdomains
+// ("*.example.com"), or a leading dot matching a domain and its subdomains (".example.com").
+//
+// The transport is configured like Transport; only its proxy selection differs. If the proxy
+// URL cannot be parsed, proxied requests fail with the parse error instead of going direct.
+func (p *ProxyConfig) HTTPClientWithBypass(patterns []string) *http.Client {
+	transport := p.Transport()
+	proxyURL, err := url.Parse(p.ProxyURL())
+	if err != nil {
+		err = fmt.Errorf("invalid proxy URL: %w", err)
+		transport.Proxy = bypassProxyFunc(nil, patterns, err)
+	} else {
+		transport.Proxy = bypassProxyFunc(proxyURL, patterns, nil)
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   p.client.Timeout,
+	}
+}
+
 // ProxyURL returns the HTTP proxy URL
 func (p *ProxyConfig) ProxyURL() string {
 	return buildProxyURL("http", p.Host, p.HTTPPort, p.Username, p.Password)
@@ -221,6 +579,154 @@ func (p *ProxyConfig) HTTPSProxyURL() string {
 	return buildProxyURL("https", p.Host, p.HTTPPort, p.Username, p.Password)
 }
 
+// SOCKS5ProxyURL returns the SOCKS5 proxy URL, using the socks5h scheme unless LocalDNS is set
+func (p *ProxyConfig) SOCKS5ProxyURL() string {
+	return buildProxyURL(socks5Scheme(p.options), p.Host, p.SOCKS5Port, p.Username, p.Password)
+}
+
+// EnvVars returns the standard proxy environment variables for the configuration.
+// HTTP_PROXY and HTTPS_PROXY use the HTTP proxy (HTTPS traffic is tunnelled via CONNECT);
+// ALL_PROXY uses the SOCKS5 proxy when a SOCKS5 port is configured.
+func (p *ProxyConfig) EnvVars() map[string]string {
+	vars := map[string]string{
+		"HTTP_PROXY":  p.ProxyURL(),
+		"HTTPS_PROXY": p.ProxyURL(),
+		"ALL_PROXY":   p.ProxyURL(),
+	}
+	if p.SOCKS5Port > 0 {
+		vars["ALL_PROXY"] = p.SOCKS5ProxyURL()
+	}
+	return vars
+}
+
+// WriteEnvFile writes EnvVars to path as KEY='value' lines that can be sourced by a shell
+// or loaded as a .env file. The file contains credentials, so it is created (or restricted)
+// with 0600 permissions.
+func (p *ProxyConfig) WriteEnvFile(path string) error {
+	vars := p.EnvVars()
+	keys := make([]string, 0, len(vars))
+	for key := range vars {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, key := range keys {
+		fmt.Fprintf(&b, "%s='%s'\n", key, strings.ReplaceAll(vars[key], "'", `'\''`))
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create env file: %w", err)
+	}
+	defer file.Close()
+
+	// OpenFile keeps the mode of an existing file, so restrict it explicitly
+	if err := file.Chmod(0600); err != nil {
+		return fmt.Errorf("failed to restrict env file permissions: %w", err)
+	}
+	if _, err := file.WriteString(b.String()); err != nil {
+		return fmt.Errorf("failed to write env file: %w", err)
+	}
+	return file.Close()
+}
+
+// GeneratePAC returns a proxy auto-config (PAC) script that routes traffic through the proxy
+// gateway and connects directly to hosts matching the bypass patterns (same syntax as
+// HTTPClientWithBypass). HTTPS URLs are tunnelled through the same PROXY entry via CONNECT.
+// PAC files cannot carry credentials; the browser or application prompts for the proxy
+// username and password, or IP whitelisting must be used.
+func (p *ProxyConfig) GeneratePAC(bypass []string) string {
+	var b strings.Builder
+
+	b.WriteString("function FindProxyForURL(url, host) {\n")
+	b.WriteString("  host = host.toLowerCase();\n")
+
+	var conditions []string
+	for _, pattern := range bypass {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		switch {
+		case pattern == "":
+			continue
+		case pattern == "*":
+			conditions = append(conditions, "true")
+		case strings.HasPrefix(pattern, "*."):
+			conditions = append(conditions, fmt.Sprintf("dnsDomainIs(host, %s)", strconv.Quote(pattern[1:])))
+		case strings.HasPrefix(pattern, "."):
+			conditions = append(conditions, fmt.Sprintf("host == %s || dnsDomainIs(host, %s)",
+				strconv.Quote(pattern[1:]), strconv.Quote(pattern)))
+		default:
+			conditions = append(conditions, fmt.Sprintf("host == %s", strconv.Quote(pattern)))
+		}
+	}
+	if len(conditions) > 0 {
+		fmt.Fprintf(&b, "  if (%s) {\n    return \"DIRECT\";\n  }\n", strings.Join(conditions, " ||\n      "))
+	}
+
+	fmt.Fprintf(&b, "  return \"PROXY %s\";\n", net.JoinHostPort(p.Host, strconv.Itoa(p.HTTPPort)))
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+// ThroughputTestURL is the download endpoint used by MeasureThroughput.
+// It must contain a %d verb that is replaced with the requested payload size in bytes.
+var ThroughputTestURL = "https://speed.cloudflare.com/__down?bytes=%d"
+
+// ThroughputResult holds the outcome of a proxy throughput measurement
+type ThroughputResult struct {
+	BytesRead      int64
+	Latency        time.Duration // time until response headers arrived
+	Duration       time.Duration // total time including the body transfer
+	BytesPerSecond float64       // body transfer rate
+}
+
+// MeasureThroughput downloads a payload of sizeBytes from ThroughputTestURL through the proxy
+// and reports latency and transfer rate. The context bounds the whole measurement.
+func (p *ProxyConfig) MeasureThroughput(ctx context.Context, sizeBytes int) (*ThroughputResult, error) {
+	if sizeBytes <= 0 {
+		return nil, fmt.Errorf("sizeBytes must be positive")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf(ThroughputTestURL, sizeBytes), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	clk := p.getClock()
+	start := clk.Now()
+	// The shared transport keeps repeated measurements from leaving idle connections behind
+	resp, err := p.HTTPClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("throughput request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	latency := clk.Now().Sub(start)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("throughput endpoint returned status %d", resp.StatusCode)
+	}
+
+	n, err := io.Copy(io.Discard, resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read payload: %w", err)
+	}
+	duration := clk.Now().Sub(start)
+
+	result := &ThroughputResult{
+		BytesRead: n,
+		Latency:   latency,
+		Duration:  duration,
+	}
+	if transfer := duration - latency; transfer > 0 {
+		result.BytesPerSecond = float64(n) / transfer.Seconds()
+	} else if duration > 0 {
+		result.BytesPerSecond = float64(n) / duration.Seconds()
+	}
+
+	return result, nil
+}
+
 // contextTransport wraps http.Transport to handle context cancellation
 type contextTransport struct {
 	base http.RoundTripper
@@ -0,0 +1,228 @@
+package nodemaven
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFilledEntriesInteriorGaps(t *testing.T) {
+	response := &StatisticsResponse{Results: []StatisticEntry{
+		{Date: "01-03-2024", TrafficUsed: 100, Requests: 10},
+		{Date: "04-03-2024", TrafficUsed: 400, Requests: 40},
+		{Date: "2024-03-06", TrafficUsed: 600, Requests: 60},
+	}}
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 3, 6, 23, 0, 0, 0, time.UTC)
+
+	filled := response.FilledEntries(start, end)
+	wantDates := []string{"01-03-2024", "02-03-2024", "03-03-2024", "04-03-2024", "05-03-2024", "06-03-2024"}
+	wantTraffic := []int64{100, 0, 0, 400, 0, 600}
+	if len(filled) != len(wantDates) {
+		t.Fatalf("got %d entries, want %d: %+v", len(filled), len(wantDates), filled)
+	}
+	for i, entry := range filled {
+		if entry.Date != wantDates[i] || entry.TrafficUsed != wantTraffic[i] {
+			t.Errorf("entry %d = %+v, want date %s with traffic %d", i, entry, wantDates[i], wantTraffic[i])
+		}
+	}
+}
+
+func TestFilledEntriesMergesDuplicateDays(t *testing.T) {
+	response := &StatisticsResponse{Results: []StatisticEntry{
+		{Date: "01-03-2024", TrafficUsed: 100, Requests: 10, SuccessRate: 1},
+		{Date: "2024-03-01", TrafficUsed: 50, Requests: 30, SuccessRate: 0.5},
+	}}
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	filled := response.FilledEntries(day, day)
+	if len(filled) != 1 || filled[0].TrafficUsed != 150 || filled[0].Requests != 40 || filled[0].SuccessRate != 0.625 {
+		t.Errorf("FilledEntries = %+v, want one merged entry", filled)
+	}
+}
+
+func TestFlexInt64(t *testing.T) {
+	valid := map[string]int64{
+		`1234`:                   1234,
+		`"1234"`:                 1234,
+		`1234.6`:                 1235,
+		`"1234.4"`:               1234,
+		`1.5e3`:                  1500,
+		`-7`:                     -7,
+		`""`:                     0,
+		`null`:                   0,
+		`9223372036854775807`:    9223372036854775807,
+		`"-9223372036854775808"`: -9223372036854775808,
+	}
+	for input, want := range valid {
+		var value flexInt64
+		if err := value.UnmarshalJSON([]byte(input)); err != nil || int64(value) != want {
+			t.Errorf("UnmarshalJSON(%s) = %d, %v; want %d", input, value, err, want)
+		}
+	}
+
+	for _, input := range []string{`"abc"`, `1e19`, `-1e19`, `"9.3e18"`, `"NaN"`, `"Inf"`} {
+		var value flexInt64
+		if err := value.UnmarshalJSON([]byte(input)); err == nil {
+			t.Errorf("UnmarshalJSON(%s) = %d, want an error", input, value)
+		}
+	}
+}
+
+func TestStatisticEntryTrafficFormats(t *testing.T) {
+	var entries []StatisticEntry
+	data := `[{"date": "01-03-2024", "traffic_used": "2048"}, {"date": "02-03-2024", "traffic_used": 1024.0}]`
+	if err := json.Unmarshal([]byte(data), &entries); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if entries[0].TrafficUsed != 2048 || entries[1].TrafficUsed != 1024 {
+		t.Errorf("entries = %+v, want traffic 2048 and 1024", entries)
+	}
+}
+
+// newGatewayTestClient returns a client whose API and proxy gateway are both served by one
+// httptest server: proxied requests (absolute request URIs) go to proxyHandler and API
+// requests get testUserInfo
+func newGatewayTestClient(t *testing.T, config *Config, proxyHandler http.HandlerFunc) *Client {
+	t.Helper()
+	return newGatewayTestClientWithAPI(t, config, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(testUserInfo))
+	}, proxyHandler)
+}
+
+// newGatewayTestClientWithAPI is newGatewayTestClient with API requests going to apiHandler
+func newGatewayTestClientWithAPI(t *testing.T, config *Config, apiHandler, proxyHandler http.HandlerFunc) *Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.RequestURI, "http://") {
+			proxyHandler(w, r)
+			return
+		}
+		apiHandler(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	host, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.APIKey = "test-key"
+	config.BaseURL = server.URL
+	config.ProxyHost = host
+	config.HTTPPort, _ = strconv.Atoi(port)
+
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	return client
+}
+
+func TestDialTimeoutFailsFast(t *testing.T) {
+	client := newGatewayTestClient(t, &Config{Timeout: 30 * time.Second, DialTimeout: 200 * time.Millisecond},
+		func(w http.ResponseWriter, r *http.Request) {})
+	proxyConfig, err := client.GetProxyConfig(nil)
+	if err != nil {
+		t.Fatalf("GetProxyConfig: %v", err)
+	}
+	// 10.255.255.1 is not routable, so connecting to it hangs until the dial timeout
+	proxyConfig.Host = "10.255.255.1"
+
+	start := time.Now()
+	_, err = proxyConfig.HTTPClient().Get("http://target.example.com/")
+	elapsed := time.Since(start)
+
+	var netErr net.Error
+	if !errors.As(err, &netErr) || !netErr.Timeout() {
+		t.Skipf("dialing a non-routable address did not time out here: %v", err)
+	}
+	if elapsed > 5*time.Second {
+		t.Errorf("request failed after %v, want the 200ms dial timeout rather than the 30s overall timeout", elapsed)
+	}
+}
+
+func TestOverallTimeoutBoundsSlowTransfers(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	client := newGatewayTestClient(t, &Config{Timeout: 200 * time.Millisecond, DialTimeout: 5 * time.Second},
+		func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+		})
+	proxyConfig, err := client.GetProxyConfig(nil)
+	if err != nil {
+		t.Fatalf("GetProxyConfig: %v", err)
+	}
+
+	start := time.Now()
+	_, err = proxyConfig.HTTPClient().Get("http://target.example.com/slow")
+	if err == nil {
+		t.Fatal("request to a stalled target succeeded, want the overall timeout")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("request failed after %v, want the 200ms overall timeout", elapsed)
+	}
+}
+
+func TestDialTimeoutDoesNotCutSlowTransfers(t *testing.T) {
+	client := newGatewayTestClient(t, &Config{Timeout: 10 * time.Second, DialTimeout: 100 * time.Millisecond},
+		func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(300 * time.Millisecond)
+			w.Write([]byte("done"))
+		})
+	proxyConfig, err := client.GetProxyConfig(nil)
+	if err != nil {
+		t.Fatalf("GetProxyConfig: %v", err)
+	}
+
+	resp, err := proxyConfig.HTTPClient().Get("http://target.example.com/slow")
+	if err != nil {
+		t.Fatalf("slow transfer failed: %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestMeasureThroughputReusesConnections(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		remotes = map[string]bool{}
+	)
+	client := newGatewayTestClient(t, &Config{}, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		remotes[r.RemoteAddr] = true
+		mu.Unlock()
+		n, _ := strconv.Atoi(r.URL.Query().Get("bytes"))
+		w.Write(make([]byte, n))
+	})
+	proxyConfig, err := client.GetProxyConfig(nil)
+	if err != nil {
+		t.Fatalf("GetProxyConfig: %v", err)
+	}
+
+	original := ThroughputTestURL
+	ThroughputTestURL = "http://speed.example.com/down?bytes=%d"
+	defer func() { ThroughputTestURL = original }()
+
+	for i := 0; i < 3; i++ {
+		result, err := proxyConfig.MeasureThroughput(context.Background(), 4096)
+		if err != nil {
+			t.Fatalf("MeasureThroughput: %v", err)
+		}
+		if result.BytesRead != 4096 {
+			t.Errorf("BytesRead = %d, want 4096", result.BytesRead)
+		}
+	}
+	if len(remotes) != 1 {
+		t.Errorf("measurements used %d gateway connections, want 1 reused connection", len(remotes))
+	}
+}
@@ -0,0 +1,77 @@
+package nodemaven
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// NewUsageSummary builds a usage snapshot from account information and statistics entries
+func NewUsageSummary(userInfo *UserInfo, entries []StatisticEntry) *UsageSummary {
+	return newUsageSummary(userInfo, entries, systemClock.Now())
+}
+
+// newUsageSummary builds a usage snapshot captured at capturedAt
+func newUsageSummary(userInfo *UserInfo, entries []StatisticEntry, capturedAt time.Time) *UsageSummary {
+	summary := &UsageSummary{CapturedAt: capturedAt}
+	if userInfo != nil {
+		summary.TrafficUsed = userInfo.TrafficUsed
+		summary.TrafficLimit = userInfo.TrafficLimit
+	}
+
+	var weighted float64
+	for _, entry := range entries {
+		summary.Requests += entry.Requests
+		weighted += entry.SuccessRate * float64(entry.Requests)
+	}
+	if summary.Requests > 0 {
+		summary.SuccessRate = weighted / float64(summary.Requests)
+	}
+
+	return summary
+}
+
+// GetUsageSummary captures a usage snapshot from the account information and the
+// statistics selected by req
+func (c *Client) GetUsageSummary(ctx context.Context, req *StatisticsRequest) (*UsageSummary, error) {
+	userInfo, err := c.GetUserInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := c.GetAllStatistics(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get statistics: %w", err)
+	}
+
+	return newUsageSummary(userInfo, entries, c.getClock().Now()), nil
+}
+
+// DiffUsage returns the usage consumed between two snapshots. If after reports less
+// traffic or fewer requests than before (a plan reset), Reset is set and after's own
+// totals are reported as the usage since the reset.
+func DiffUsage(before, after *UsageSummary) *UsageDelta {
+	if before == nil {
+		before = &UsageSummary{}
+	}
+	if after == nil {
+		after = &UsageSummary{}
+	}
+
+	delta := &UsageDelta{
+		TrafficUsed:       after.TrafficUsed - before.TrafficUsed,
+		Requests:          after.Requests - before.Requests,
+		SuccessRateChange: after.SuccessRate - before.SuccessRate,
+	}
+	if !before.CapturedAt.IsZero() && !after.CapturedAt.IsZero() {
+		delta.Elapsed = after.CapturedAt.Sub(before.CapturedAt)
+	}
+
+	if delta.TrafficUsed < 0 || delta.Requests < 0 {
+		delta.Reset = true
+		delta.TrafficUsed = after.TrafficUsed
+		delta.Requests = after.Requests
+	}
+
+	return delta
+}
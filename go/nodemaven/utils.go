@@ -1,12 +1,17 @@
 package nodemaven
 
 import (
+	"context"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"hash/crc32"
 	"io"
 	"math"
+	"net"
 	"net/http"
 	"net/url"
 	"regexp"
@@ -58,7 +63,7 @@ func ValidateProxyPassword(password string) bool {
 
 // ValidateDateFormat validates date string in dd-mm-yyyy format
 func ValidateDateFormat(dateString string) error {
-	_, err := time.Parse("02-01-2006", dateString)
+	_, err := time.Parse(statisticsDateFormat, dateString)
 	if err != nil {
 		return fmt.Errorf("invalid date format for '%s'. Expected dd-mm-yyyy", dateString)
 	}
@@ -72,12 +77,128 @@ func GenerateSessionID() string {
 	return hex.EncodeToString(bytes)[:13]
 }
 
+// DeriveSessionID derives a session ID from seed deterministically, so the same key (e.g. a
+// user or account ID) maps to the same sticky session across process restarts. The result
+// has the same 13 lowercase hex characters as GenerateSessionID.
+func DeriveSessionID(seed string) string {
+	sum := sha256.Sum256([]byte(seed))
+	return hex.EncodeToString(sum[:7])[:13]
+}
+
+// copyProxyOptions returns a deep copy of options
+func copyProxyOptions(options *ProxyOptions) *ProxyOptions {
+	if options == nil {
+		return nil
+	}
+	copied := *options
+	copied.Regions = append([]string(nil), options.Regions...)
+	copied.Cities = append([]string(nil), options.Cities...)
+	return &copied
+}
+
+// MergeProxyOptions returns options layered over defaults: every field set in options wins,
+// and unset fields fall back to defaults. Neither argument is modified.
+//
+// The LocalDNS and AutoSession booleans cannot tell false from unset, so they are ORed: an
+// override can turn them on but never off. To disable one that defaults set, pass options
+// built from a copy of defaults with the field cleared instead of merging.
+func MergeProxyOptions(defaults, options *ProxyOptions) *ProxyOptions {
+	if defaults == nil {
+		return options
+	}
+	if options == nil {
+		merged := *defaults
+		return &merged
+	}
+
+	merged := *options
+	mergeString := func(value *string, fallback string) {
+		if *value == "" {
+			*value = fallback
+		}
+	}
+	mergeString(&merged.Country, defaults.Country)
+	mergeString(&merged.ISP, defaults.ISP)
+	mergeString(&merged.ZipCode, defaults.ZipCode)
+	mergeString(&merged.ASN, defaults.ASN)
+	mergeString(&merged.Session, defaults.Session)
+	mergeString(&merged.ConnectionType, defaults.ConnectionType)
+	mergeString(&merged.Protocol, defaults.Protocol)
+	mergeString(&merged.OS, defaults.OS)
+	mergeString(&merged.Browser, defaults.Browser)
+
+	// Singular and multi-value location fields are treated as one field each
+	if merged.Region == "" && len(merged.Regions) == 0 {
+		merged.Region = defaults.Region
+		merged.Regions = defaults.Regions
+	}
+	if merged.City == "" && len(merged.Cities) == 0 {
+		merged.City = defaults.City
+		merged.Cities = defaults.Cities
+	}
+
+	// false means unset for booleans, so a default that is on stays on
+	merged.LocalDNS = merged.LocalDNS || defaults.LocalDNS
+	merged.AutoSession = merged.AutoSession || defaults.AutoSession
+
+	return &merged
+}
+
+// NormalizeOptions returns a copy of options with every targeting value normalized exactly
+// as buildProxyUsername sends it (lowercased; spaces and underscores stripped from region,
+// city and ISP), so callers can inspect or log what the gateway will actually receive
+func NormalizeOptions(options *ProxyOptions) *ProxyOptions {
+	if options == nil {
+		return nil
+	}
+
+	normalized := copyProxyOptions(options)
+	normalized.Country = strings.ToLower(options.Country)
+	normalized.Region = normalizeTargetingValue(options.Region)
+	normalized.City = normalizeTargetingValue(options.City)
+	normalized.ISP = normalizeTargetingValue(options.ISP)
+	normalized.ConnectionType = strings.ToLower(options.ConnectionType)
+	normalized.Protocol = strings.ToLower(options.Protocol)
+	normalized.OS = strings.ToLower(options.OS)
+	normalized.Browser = strings.ToLower(options.Browser)
+	normalized.Regions = normalizeTargetingValues(options.Regions)
+	normalized.Cities = normalizeTargetingValues(options.Cities)
+
+	return normalized
+}
+
+// UsernameFormat serializes a base username and targeting options into a proxy username.
+// Implementations allow alternate gateway username grammars to be plugged into a Client.
+type UsernameFormat interface {
+	BuildUsername(baseUsername string, options *ProxyOptions) (string, error)
+}
+
+// UsernameFormatFunc adapts a function to the UsernameFormat interface
+type UsernameFormatFunc func(baseUsername string, options *ProxyOptions) (string, error)
+
+// BuildUsername calls f(baseUsername, options)
+func (f UsernameFormatFunc) BuildUsername(baseUsername string, options *ProxyOptions) (string, error) {
+	return f(baseUsername, options)
+}
+
+// DefaultUsernameFormat is the current NodeMaven username grammar, matching the Python SDK
+type DefaultUsernameFormat struct{}
+
+// BuildUsername builds the username in the default token order
+func (DefaultUsernameFormat) BuildUsername(baseUsername string, options *ProxyOptions) (string, error) {
+	return buildProxyUsername(baseUsername, options)
+}
+
+// MaxProxyUsernameLength is the longest proxy username accepted by the gateway
+const MaxProxyUsernameLength = 100
+
 // buildProxyUsername builds NodeMaven proxy username with targeting parameters
 // Format matches Python implementation exactly: base_username-country-us-region-california-city-newyork-ipv4-true-sid-sessionid-filter-medium
-func buildProxyUsername(baseUsername string, options *ProxyOptions) string {
+// An error is returned if the resulting username exceeds MaxProxyUsernameLength.
+func buildProxyUsername(baseUsername string, options *ProxyOptions) (string, error) {
 	if options == nil {
 		// Even with no options, we need the required default parameters
-		return baseUsername + "-ipv4-true-filter-medium"
+		return checkProxyUsernameLength(baseUsername + "-ipv4-true-filter-medium")
 	}
 
 	parts := []string{baseUsername}
@@ -86,20 +207,36 @@ func buildProxyUsername(baseUsername string, options *ProxyOptions) string {
 	if options.Country != "" {
 		parts = append(parts, "country", strings.ToLower(options.Country))
 	}
+	if options.Region != "" && len(options.Regions) > 0 {
+		return "", &UsernameError{Reason: "Region and Regions cannot be combined"}
+	}
+	if options.City != "" && len(options.Cities) > 0 {
+		return "", &UsernameError{Reason: "City and Cities cannot be combined"}
+	}
+
+	// Convert spaces to nothing and make lowercase (like Python implementation)
 	if options.Region != "" {
-		// Convert spaces to nothing and make lowercase (like Python implementation)
-		region := strings.ToLower(strings.ReplaceAll(strings.ReplaceAll(options.Region, " ", ""), "_", ""))
-		parts = append(parts, "region", region)
+		parts = append(parts, "region", normalizeTargetingValue(options.Region))
+	}
+	if len(options.Regions) > 0 {
+		regions, err := joinTargetingValues("Regions", options.Regions)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, "region", regions)
 	}
 	if options.City != "" {
-		// Convert spaces to nothing and make lowercase (like Python implementation)
-		city := strings.ToLower(strings.ReplaceAll(strings.ReplaceAll(options.City, " ", ""), "_", ""))
-		parts = append(parts, "city", city)
+		parts = append(parts, "city", normalizeTargetingValue(options.City))
+	}
+	if len(options.Cities) > 0 {
+		cities, err := joinTargetingValues("Cities", options.Cities)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, "city", cities)
 	}
 	if options.ISP != "" {
-		// Convert spaces to nothing and make lowercase (like Python implementation)
-		isp := strings.ToLower(strings.ReplaceAll(strings.ReplaceAll(options.ISP, " ", ""), "_", ""))
-		parts = append(parts, "isp", isp)
+		parts = append(parts, "isp", normalizeTargetingValue(options.ISP))
 	}
 	if options.ZipCode != "" {
 		parts = append(parts, "zip", options.ZipCode)
@@ -135,7 +272,164 @@ func buildProxyUsername(baseUsername string, options *ProxyOptions) string {
 	// IP filter quality (always add to match Python format exactly)
 	parts = append(parts, "filter", "medium")
 
-	return strings.Join(parts, "-")
+	return checkProxyUsernameLength(strings.Join(parts, "-"))
+}
+
+// ValidateBuildable builds the proxy username for these options with the default username
+// grammar and checks the result against the gateway's rules: the base username must pass
+// ValidateProxyUsername, the whole username must fit MaxProxyUsernameLength, and every
+// targeting value may only contain lowercase letters, digits, underscores, dots and the commas
+// separating multiple values. The error names the offending key and character.
+func (o *ProxyOptions) ValidateBuildable(baseUsername string) error {
+	if !ValidateProxyUsername(baseUsername) {
+		return fmt.Errorf("base username %q must be 9-100 letters, digits or underscores", baseUsername)
+	}
+
+	username, err := buildProxyUsername(baseUsername, o)
+	if err != nil {
+		return err
+	}
+
+	tokens := strings.Split(strings.TrimPrefix(username, baseUsername+"-"), "-")
+	if len(tokens)%2 != 0 {
+		return fmt.Errorf("proxy username %q has a targeting key without a value", username)
+	}
+	for i := 0; i < len(tokens); i += 2 {
+		key, value := tokens[i], tokens[i+1]
+		if value == "" {
+			return fmt.Errorf("%s value is empty after normalization", key)
+		}
+		for _, r := range value {
+			if !isUsernameValueRune(r) {
+				return fmt.Errorf("%s value %q contains invalid character %q", key, value, r)
+			}
+		}
+	}
+	return nil
+}
+
+// isUsernameValueRune reports whether r may appear in a targeting value of the proxy username
+func isUsernameValueRune(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '_' || r == '.' || r == ','
+}
+
+// ParseProxyUsername reverses buildProxyUsername: it splits a built proxy username into the
+// base username and the targeting options it encodes. Values come back normalized as they
+// were sent. The fixed ipv4 and filter tokens are skipped, as are unknown keys, so usernames
+// from newer gateway grammars still parse.
+func ParseProxyUsername(username string) (*ProxyOptions, string, error) {
+	parts := strings.Split(username, "-")
+	if parts[0] == "" {
+		return nil, "", fmt.Errorf("proxy username is empty")
+	}
+	base := parts[0]
+	tokens := parts[1:]
+	if len(tokens)%2 != 0 {
+		return nil, "", fmt.Errorf("proxy username %q has a targeting key without a value", username)
+	}
+
+	options := &ProxyOptions{}
+	for i := 0; i < len(tokens); i += 2 {
+		key, value := tokens[i], tokens[i+1]
+		switch key {
+		case "country":
+			options.Country = value
+		case "region":
+			if strings.Contains(value, ",") {
+				options.Regions = strings.Split(value, ",")
+			} else {
+				options.Region = value
+			}
+		case "city":
+			if strings.Contains(value, ",") {
+				options.Cities = strings.Split(value, ",")
+			} else {
+				options.City = value
+			}
+		case "isp":
+			options.ISP = value
+		case "zip":
+			options.ZipCode = value
+		case "asn":
+			options.ASN = value
+		case "type":
+			options.ConnectionType = value
+		case "sid":
+			options.Session = value
+		case "protocol":
+			options.Protocol = value
+		case "os":
+			options.OS = value
+		case "browser":
+			options.Browser = value
+		}
+	}
+
+	return options, base, nil
+}
+
+// usernameChecksumKey is the token introducing the checksum appended by SignProxyUsername
+const usernameChecksumKey = "ck"
+
+// SignProxyUsername appends a short CRC-32 checksum token ("-ck-1a2b3c4d") to a built
+// proxy username, so corruption is detectable after it passes through logs or configs.
+// The checksum is client-side only: the gateway does not understand it, so always strip it
+// with VerifyProxyUsername before using the username for authentication.
+func SignProxyUsername(username string) string {
+	return fmt.Sprintf("%s-%s-%08x", username, usernameChecksumKey, crc32.ChecksumIEEE([]byte(username)))
+}
+
+// VerifyProxyUsername checks the checksum added by SignProxyUsername and returns the
+// username without it. ErrUsernameChecksum is returned if the token is missing or does not match.
+func VerifyProxyUsername(signed string) (string, error) {
+	i := strings.LastIndex(signed, "-"+usernameChecksumKey+"-")
+	if i < 0 {
+		return "", fmt.Errorf("%w: no checksum token", ErrUsernameChecksum)
+	}
+
+	username, checksum := signed[:i], signed[i+len(usernameChecksumKey)+2:]
+	if checksum != fmt.Sprintf("%08x", crc32.ChecksumIEEE([]byte(username))) {
+		return "", ErrUsernameChecksum
+	}
+	return username, nil
+}
+
+// normalizeTargetingValue strips spaces and underscores and lowercases a targeting value
+func normalizeTargetingValue(value string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.ReplaceAll(value, " ", ""), "_", ""))
+}
+
+// normalizeTargetingValues normalizes each value, dropping those that normalize to empty
+func normalizeTargetingValues(values []string) []string {
+	if values == nil {
+		return nil
+	}
+	normalized := make([]string, 0, len(values))
+	for _, value := range values {
+		if v := normalizeTargetingValue(value); v != "" {
+			normalized = append(normalized, v)
+		}
+	}
+	return normalized
+}
+
+// joinTargetingValues normalizes each value and joins them into the comma-separated multi-value format.
+// An error naming field is returned if no value is left after normalization.
+func joinTargetingValues(field string, values []string) (string, error) {
+	normalized := normalizeTargetingValues(values)
+	if len(normalized) == 0 {
+		return "", &UsernameError{Reason: fmt.Sprintf("%s contains no non-empty values", field)}
+	}
+	return strings.Join(normalized, ","), nil
+}
+
+// checkProxyUsernameLength rejects built usernames the gateway would not accept
+func checkProxyUsernameLength(username string) (string, error) {
+	if len(username) > MaxProxyUsernameLength {
+		return "", &UsernameError{Reason: fmt.Sprintf("proxy username is %d characters long, exceeding the %d character limit; reduce targeting options or shorten the session ID",
+			len(username), MaxProxyUsernameLength)}
+	}
+	return username, nil
 }
 
 // buildProxyURL builds a proxy URL
@@ -143,8 +437,22 @@ func buildProxyURL(protocol, host string, port int, username, password string) s
 	return fmt.Sprintf("%s://%s:%s@%s:%d", protocol, username, password, host, port)
 }
 
+// socks5Scheme returns "socks5h" for proxy-side DNS resolution, or "socks5" when LocalDNS is requested
+func socks5Scheme(options *ProxyOptions) string {
+	if options != nil && options.LocalDNS {
+		return "socks5"
+	}
+	return "socks5h"
+}
+
 // GetCurrentIP fetches current IP address using the provided HTTP client
 func GetCurrentIP(client *http.Client) (string, error) {
+	return GetCurrentIPContext(context.Background(), client)
+}
+
+// GetCurrentIPContext fetches current IP address using the provided HTTP client,
+// honoring the context for cancellation and deadlines
+func GetCurrentIPContext(ctx context.Context, client *http.Client) (string, error) {
 	if client == nil {
 		client = &http.Client{Timeout: 10 * time.Second}
 	}
@@ -156,56 +464,154 @@ func GetCurrentIP(client *http.Client) (string, error) {
 		"https://ip-api.com/json?fields=query",
 	}
 
+	var lastErr error
 	for _, service := range services {
-		resp, err := client.Get(service)
-		if err != nil {
-			continue
+		if err := ctx.Err(); err != nil {
+			return "", err
 		}
-		defer resp.Body.Close()
 
-		if resp.StatusCode != http.StatusOK {
-			continue
-		}
-
-		// Parse JSON response based on service
-		var result map[string]interface{}
-		if err := parseJSONResponse(resp, &result); err != nil {
+		// Extract IP from different response formats
+		result, err := fetchJSON(ctx, client, service)
+		if err != nil {
+			lastErr = err
 			continue
 		}
-
-		// Extract IP from different response formats
 		if ip := extractIPFromResponse(result); ip != "" {
 			return ip, nil
 		}
 	}
 
+	if lastErr != nil {
+		return "", fmt.Errorf("failed to get current IP from any service: %w", lastErr)
+	}
 	return "", fmt.Errorf("failed to get current IP from any service")
 }
 
+// AssertRotation checks that client's exit IP changes between two consecutive requests.
+// Idle connections are closed in between so a kept-alive proxy tunnel cannot pin the IP.
+// A failed IP check is reported as err (with changed false), distinct from an unchanged IP,
+// which returns changed false and a nil error.
+func AssertRotation(ctx context.Context, client *http.Client) (changed bool, oldIP, newIP string, err error) {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	oldIP, err = GetCurrentIPContext(ctx, client)
+	if err != nil {
+		return false, "", "", fmt.Errorf("first IP check failed: %w", err)
+	}
+
+	client.CloseIdleConnections()
+
+	newIP, err = GetCurrentIPContext(ctx, client)
+	if err != nil {
+		return false, oldIP, "", fmt.Errorf("second IP check failed: %w", err)
+	}
+
+	return oldIP != newIP, oldIP, newIP, nil
+}
+
 // CheckIPWithDetails fetches detailed IP information
 func CheckIPWithDetails(client *http.Client) (map[string]interface{}, error) {
+	return CheckIPWithDetailsContext(context.Background(), client)
+}
+
+// ipDetailsURL is the geolocation service queried by CheckIPWithDetails and CheckIPDetails
+const ipDetailsURL = "https://ip-api.com/json"
+
+// CheckIPWithDetailsContext fetches detailed IP information, honoring the context
+func CheckIPWithDetailsContext(ctx context.Context, client *http.Client) (map[string]interface{}, error) {
 	if client == nil {
 		client = &http.Client{Timeout: 10 * time.Second}
 	}
 
-	resp, err := client.Get("https://ip-api.com/json")
+	result, err := fetchJSON(ctx, client, ipDetailsURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get IP details: %w", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("IP service returned status %d", resp.StatusCode)
+	return result, nil
+}
+
+// CheckIPDetails fetches typed geolocation details for the client's current exit IP
+func CheckIPDetails(ctx context.Context, client *http.Client) (*IPDetails, error) {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
 	}
 
-	var result map[string]interface{}
-	if err := parseJSONResponse(resp, &result); err != nil {
-		return nil, fmt.Errorf("failed to parse IP details response: %w", err)
+	details := &IPDetails{}
+	if err := fetchJSONInto(ctx, client, ipDetailsURL, details); err != nil {
+		return nil, fmt.Errorf("failed to get IP details: %w", err)
+	}
+	if details.Status != "" && details.Status != "success" {
+		return nil, fmt.Errorf("IP details lookup failed with status %q", details.Status)
+	}
+
+	return details, nil
+}
+
+// earthRadiusKm is the mean Earth radius used for great-circle distances
+const earthRadiusKm = 6371.0
+
+// GeoDistance returns the great-circle (Haversine) distance in kilometres between two
+// geolocated IPs, or ErrMissingCoordinates if either lacks coordinates
+func GeoDistance(a, b *IPDetails) (float64, error) {
+	if !a.HasCoordinates() || !b.HasCoordinates() {
+		return 0, ErrMissingCoordinates
+	}
+	return haversineKm(*a.Latitude, *a.Longitude, *b.Latitude, *b.Longitude), nil
+}
+
+// GeoDistanceKm returns the great-circle distance in kilometres between two geolocated IPs.
+// It returns NaN if either lacks coordinates; use GeoDistance to get an error instead.
+func GeoDistanceKm(a, b *IPDetails) float64 {
+	distance, err := GeoDistance(a, b)
+	if err != nil {
+		return math.NaN()
 	}
+	return distance
+}
+
+// haversineKm computes the great-circle distance between two points given in degrees
+func haversineKm(lat1, lon1, lat2, lon2 float64) float64 {
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLat := toRad(lat2 - lat1)
+	dLon := toRad(lon2 - lon1)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
 
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// fetchJSON GETs a URL and decodes its JSON object response, always closing the body
+func fetchJSON(ctx context.Context, client *http.Client, rawURL string) (map[string]interface{}, error) {
+	var result map[string]interface{}
+	if err := fetchJSONInto(ctx, client, rawURL, &result); err != nil {
+		return nil, err
+	}
 	return result, nil
 }
 
+// fetchJSONInto GETs a URL and decodes its JSON response into target, always closing the body
+func fetchJSONInto(ctx context.Context, client *http.Client, rawURL string, target interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return &ipServiceStatusError{StatusCode: resp.StatusCode}
+	}
+	return parseJSONResponse(resp, target)
+}
+
 // IPChecker represents an IP checking service
 type IPChecker struct {
 	Name string
@@ -224,16 +630,52 @@ func GetSupportedIPCheckers() []IPChecker {
 
 // TestProxyConnection tests a proxy connection and returns the IP address
 func TestProxyConnection(proxyConfig *ProxyConfig, description string) (string, error) {
+	return TestProxyConnectionContext(context.Background(), proxyConfig, description)
+}
+
+// TestProxyConnectionContext tests a proxy connection and returns the IP address, honoring the context.
+// Failures are returned as *ProxyTestError, classifying the cause for diagnostics.
+func TestProxyConnectionContext(ctx context.Context, proxyConfig *ProxyConfig, description string) (string, error) {
 	client := proxyConfig.HTTPClient()
 
-	ip, err := GetCurrentIP(client)
+	ip, err := GetCurrentIPContext(ctx, client)
 	if err != nil {
-		return "", fmt.Errorf("%s failed: %w", description, err)
+		return "", &ProxyTestError{Kind: classifyProxyFailure(err), Description: description, Err: err}
 	}
 
 	return ip, nil
 }
 
+// classifyProxyFailure determines why a request through the proxy failed
+func classifyProxyFailure(err error) ProxyFailureKind {
+	var statusErr *ipServiceStatusError
+	if errors.As(err, &statusErr) {
+		if statusErr.StatusCode == http.StatusProxyAuthRequired {
+			return ProxyFailureAuth
+		}
+		return ProxyFailureTarget
+	}
+	if isProxyAuthFailure(nil, err) {
+		return ProxyFailureAuth
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return ProxyFailureTimeout
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && (opErr.Op == "proxyconnect" || opErr.Op == "dial") {
+		return ProxyFailureConnection
+	}
+
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return ProxyFailureTarget
+	}
+	return ProxyFailureUnknown
+}
+
 // Helper functions for JSON parsing
 
 func parseJSONResponse(resp *http.Response, target interface{}) error {
@@ -267,6 +709,44 @@ func extractIPFromResponse(result map[string]interface{}) string {
 	return ""
 }
 
+// bypassProxyFunc returns an http.Transport proxy function that skips the proxy for matching hosts
+// and returns proxyURL, or proxyErr when set, for all others
+func bypassProxyFunc(proxyURL *url.URL, patterns []string, proxyErr error) func(*http.Request) (*url.URL, error) {
+	return func(req *http.Request) (*url.URL, error) {
+		host := req.URL.Hostname()
+		for _, pattern := range patterns {
+			if MatchHostPattern(host, pattern) {
+				return nil, nil
+			}
+		}
+		return proxyURL, proxyErr
+	}
+}
+
+// MatchHostPattern reports whether host matches a bypass pattern.
+// Matching is case-insensitive and ignores ports.
+func MatchHostPattern(host, pattern string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if pattern == "" || host == "" {
+		return false
+	}
+
+	switch {
+	case pattern == "*":
+		return true
+	case strings.HasPrefix(pattern, "*."):
+		return strings.HasSuffix(host, pattern[1:])
+	case strings.HasPrefix(pattern, "."):
+		return host == pattern[1:] || strings.HasSuffix(host, pattern)
+	default:
+		return host == pattern
+	}
+}
+
 // BuildHTTPProxyFromURL creates a proxy function from a proxy URL
 func BuildHTTPProxyFromURL(proxyURL string) func(*http.Request) (*url.URL, error) {
 	return func(req *http.Request) (*url.URL, error) {
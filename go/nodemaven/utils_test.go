@@ -0,0 +1,170 @@
+package nodemaven
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestMergeProxyOptions(t *testing.T) {
+	defaults := &ProxyOptions{
+		Country:  "de",
+		Region:   "berlin",
+		ISP:      "telekom",
+		ZipCode:  "10115",
+		LocalDNS: true,
+	}
+	options := &ProxyOptions{Country: "fr", Cities: []string{"paris", "lyon"}}
+
+	merged := MergeProxyOptions(defaults, options)
+	want := &ProxyOptions{
+		Country:  "fr",
+		Region:   "berlin",
+		Cities:   []string{"paris", "lyon"},
+		ISP:      "telekom",
+		ZipCode:  "10115",
+		LocalDNS: true,
+	}
+	if !reflect.DeepEqual(merged, want) {
+		t.Errorf("MergeProxyOptions = %+v, want %+v", merged, want)
+	}
+	if options.Region != "" || defaults.Country != "de" {
+		t.Error("MergeProxyOptions modified its arguments")
+	}
+
+	// A multi-value field set per call replaces the singular default
+	merged = MergeProxyOptions(defaults, &ProxyOptions{Regions: []string{"bavaria", "hesse"}})
+	if merged.Region != "" || len(merged.Regions) != 2 {
+		t.Errorf("Regions override: Region = %q, Regions = %v", merged.Region, merged.Regions)
+	}
+
+	// Booleans cannot be switched off by an override
+	if merged := MergeProxyOptions(defaults, &ProxyOptions{LocalDNS: false}); !merged.LocalDNS {
+		t.Error("LocalDNS default was turned off by a zero-valued override")
+	}
+
+	if merged := MergeProxyOptions(defaults, nil); merged == defaults || merged.Country != "de" {
+		t.Errorf("MergeProxyOptions(defaults, nil) = %+v, want a copy of defaults", merged)
+	}
+	if merged := MergeProxyOptions(nil, options); merged.Country != "fr" {
+		t.Errorf("MergeProxyOptions(nil, options) = %+v, want options", merged)
+	}
+}
+
+func TestDefaultProxyOptionsInProxyConfig(t *testing.T) {
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(testUserInfo))
+	})
+	client.DefaultProxyOptions = &ProxyOptions{Country: "de", ISP: "telekom"}
+
+	proxyConfig, err := client.GetProxyConfig(&ProxyOptions{Country: "fr"})
+	if err != nil {
+		t.Fatalf("GetProxyConfig: %v", err)
+	}
+	if want := "test_user-country-fr-isp-telekom-ipv4-true-filter-medium"; proxyConfig.Username != want {
+		t.Errorf("Username = %q, want %q", proxyConfig.Username, want)
+	}
+}
+
+// rewriteTransport sends every request to target, keeping the request's path and query
+type rewriteTransport struct {
+	target *url.URL
+}
+
+func (r rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = r.target.Scheme
+	req.URL.Host = r.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// newRewriteClient returns an http.Client whose requests are all served by handler
+func newRewriteClient(t *testing.T, handler http.HandlerFunc) *http.Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	target, _ := url.Parse(server.URL)
+	return &http.Client{Transport: rewriteTransport{target: target}}
+}
+
+func TestCheckIPDetails(t *testing.T) {
+	httpClient := newRewriteClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"query": "203.0.113.7", "status": "success", "country": "Germany",
+			"countryCode": "DE", "city": "Berlin", "lat": 52.52, "lon": 13.405, "isp": "Example ISP"}`))
+	})
+
+	details, err := CheckIPDetails(context.Background(), httpClient)
+	if err != nil {
+		t.Fatalf("CheckIPDetails: %v", err)
+	}
+	if details.IP != "203.0.113.7" || details.CountryCode != "DE" || !details.HasCoordinates() || *details.Latitude != 52.52 {
+		t.Errorf("CheckIPDetails = %+v", details)
+	}
+
+	failing := newRewriteClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status": "fail", "message": "reserved range"}`))
+	})
+	if _, err := CheckIPDetails(context.Background(), failing); err == nil {
+		t.Error("CheckIPDetails succeeded on a failed lookup")
+	}
+}
+
+func TestGeoDistance(t *testing.T) {
+	point := func(lat, lon float64) *IPDetails { return &IPDetails{Latitude: &lat, Longitude: &lon} }
+	tests := []struct {
+		name string
+		a, b *IPDetails
+		want float64
+	}{
+		{"London-Paris", point(51.5074, -0.1278), point(48.8566, 2.3522), 343.6},
+		{"New York-Los Angeles", point(40.7128, -74.0060), point(34.0522, -118.2437), 3935.7},
+		{"Sydney-Tokyo", point(-33.8688, 151.2093), point(35.6762, 139.6503), 7825.8},
+		{"same point", point(10, 20), point(10, 20), 0},
+	}
+	for _, tt := range tests {
+		got, err := GeoDistance(tt.a, tt.b)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if math.Abs(got-tt.want) > 0.5 {
+			t.Errorf("%s: GeoDistance = %.1f km, want %.1f km", tt.name, got, tt.want)
+		}
+		if reverse, _ := GeoDistance(tt.b, tt.a); math.Abs(reverse-got) > 1e-9 {
+			t.Errorf("%s: GeoDistance is not symmetric", tt.name)
+		}
+	}
+
+	if _, err := GeoDistance(point(1, 2), &IPDetails{}); !errors.Is(err, ErrMissingCoordinates) {
+		t.Errorf("GeoDistance without coordinates error = %v, want ErrMissingCoordinates", err)
+	}
+	if !math.IsNaN(GeoDistanceKm(&IPDetails{}, point(1, 2))) {
+		t.Error("GeoDistanceKm without coordinates is not NaN")
+	}
+}
+
+func TestBuildProxyUsernameMultiValue(t *testing.T) {
+	username, err := buildProxyUsername("user", &ProxyOptions{Country: "us", Regions: []string{"New York", " ", "new_jersey"}})
+	if err != nil {
+		t.Fatalf("buildProxyUsername: %v", err)
+	}
+	if want := "user-country-us-region-newyork,newjersey-ipv4-true-filter-medium"; username != want {
+		t.Errorf("buildProxyUsername = %q, want %q", username, want)
+	}
+
+	for _, options := range []*ProxyOptions{
+		{Country: "us", Regions: []string{" "}},
+		{Country: "us", Cities: []string{"", "_"}},
+	} {
+		var usernameErr *UsernameError
+		_, err := buildProxyUsername("user", options)
+		if !errors.As(err, &usernameErr) || errors.Is(err, ErrCredentialsUnavailable) {
+			t.Errorf("buildProxyUsername(%+v) error = %v, want a *UsernameError", options, err)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package nodemaven
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// WarmConnMaxIdle is how long a connection opened by Warmup is kept before it is
+// considered stale and closed instead of being used
+var WarmConnMaxIdle = 30 * time.Second
+
+// warmConn is a pre-dialed gateway connection
+type warmConn struct {
+	net.Conn
+	opened time.Time
+}
+
+// warmConnPool hands pre-dialed gateway connections to the transport's dialer
+type warmConnPool struct {
+	addr   string
+	dialer net.Dialer
+	clock  clock
+
+	mu    sync.Mutex
+	conns []warmConn
+}
+
+// DialContext returns a warmed connection to the gateway when one is available
+// and dials a new connection otherwise
+func (w *warmConnPool) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	if addr == w.addr {
+		if conn := w.take(); conn != nil {
+			return conn, nil
+		}
+	}
+	return w.dialer.DialContext(ctx, network, addr)
+}
+
+// take pops the most recently opened connection that is not stale
+func (w *warmConnPool) take() net.Conn {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for len(w.conns) > 0 {
+		conn := w.conns[len(w.conns)-1]
+		w.conns = w.conns[:len(w.conns)-1]
+		if w.clock.Now().Sub(conn.opened) < WarmConnMaxIdle {
+			return conn.Conn
+		}
+		conn.Close()
+	}
+	return nil
+}
+
+// size returns the number of fresh warmed connections
+func (w *warmConnPool) size() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	fresh := w.conns[:0]
+	for _, conn := range w.conns {
+		if w.clock.Now().Sub(conn.opened) < WarmConnMaxIdle {
+			fresh = append(fresh, conn)
+		} else {
+			conn.Close()
+		}
+	}
+	w.conns = fresh
+	return len(w.conns)
+}
+
+// sharedTransport returns the transport shared by HTTPClient, creating it on first use
+func (p *ProxyConfig) sharedTransport() *http.Transport {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.transport == nil {
+		p.warm = &warmConnPool{
+			addr:   net.JoinHostPort(p.Host, strconv.Itoa(p.HTTPPort)),
+			dialer: net.Dialer{Timeout: p.DialTimeout},
+			clock:  p.getClock(),
+		}
+		p.transport = p.Transport()
+		p.transport.DialContext = p.warm.DialContext
+	}
+	return p.transport
+}
+
+// Warmup opens n connections to the proxy gateway ahead of time, so the first requests made
+// with HTTPClient skip the TCP handshake. The number of warm connections is capped by the
+// transport's MaxIdleConnsPerHost; connections unused after WarmConnMaxIdle are discarded.
+// Dials honor ctx; the first dial error is returned, keeping the connections that succeeded.
+func (p *ProxyConfig) Warmup(ctx context.Context, n int) error {
+	transport := p.sharedTransport()
+	if p.dryRun() {
+		return nil
+	}
+
+	limit := transport.MaxIdleConnsPerHost
+	if limit <= 0 {
+		limit = http.DefaultMaxIdleConnsPerHost
+	}
+	if missing := limit - p.warm.size(); n > missing {
+		n = missing
+	}
+
+	var (
+		wg      sync.WaitGroup
+		errOnce sync.Once
+		dialErr error
+	)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			conn, err := p.warm.dialer.DialContext(ctx, "tcp", p.warm.addr)
+			if err != nil {
+				errOnce.Do(func() { dialErr = err })
+				return
+			}
+
+			p.warm.mu.Lock()
+			p.warm.conns = append(p.warm.conns, warmConn{Conn: conn, opened: p.warm.clock.Now()})
+			p.warm.mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if dialErr != nil {
+		return fmt.Errorf("failed to warm up proxy connections: %w", dialErr)
+	}
+	return nil
+}